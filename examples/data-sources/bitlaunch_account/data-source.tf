@@ -0,0 +1,24 @@
+terraform {
+  required_providers {
+    bitlaunch = {
+      version = "0.4.0"
+      source  = "pathtofile-tf/bitlaunch"
+    }
+  }
+}
+
+variable "token" { sensitive = true }
+
+provider "bitlaunch" {
+  token = var.token
+}
+
+data "bitlaunch_account" "example" {}
+
+# Warn when there's less than a week of balance left
+check "balance" {
+  assert {
+    condition     = data.bitlaunch_account.example.cost_per_hour == 0 || data.bitlaunch_account.example.balance / data.bitlaunch_account.example.cost_per_hour > 24 * 7
+    error_message = "Less than a week of BitLaunch balance left"
+  }
+}
@@ -0,0 +1,20 @@
+terraform {
+  required_providers {
+    bitlaunch = {
+      version = "0.4.0"
+      source  = "pathtofile-tf/bitlaunch"
+    }
+  }
+}
+
+variable "token" { sensitive = true }
+
+provider "bitlaunch" {
+  token = var.token
+}
+
+data "bitlaunch_hosts" "example" {}
+
+output "resizable_hosts" {
+  value = [for host in data.bitlaunch_hosts.example.hosts : host.name if host.resize]
+}
@@ -18,3 +18,11 @@ data "bitlaunch_image" "example" {
   distro_name = "Ubuntu"
   # version_name = "20.04 (LTS) x64"
 }
+
+# The newest Ubuntu LTS, whatever it's called
+data "bitlaunch_image" "latest_lts" {
+  host              = "DigitalOcean"
+  distro_name_regex = "^Ubuntu$"
+  version_regex     = "LTS"
+  most_recent       = true
+}
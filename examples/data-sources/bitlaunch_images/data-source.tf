@@ -0,0 +1,35 @@
+terraform {
+  required_providers {
+    bitlaunch = {
+      version = "0.4.0"
+      source  = "pathtofile-tf/bitlaunch"
+    }
+  }
+}
+
+variable "token" { sensitive = true }
+
+provider "bitlaunch" {
+  token = var.token
+}
+
+# Every Ubuntu LTS version, newest first
+data "bitlaunch_images" "example" {
+  host = "DigitalOcean"
+
+  filter {
+    name   = "distro_name"
+    values = ["Ubuntu"]
+  }
+
+  filter {
+    name     = "version_name"
+    values   = ["LTS"]
+    match_by = "regex"
+  }
+
+  sort {
+    key       = "version_name"
+    direction = "desc"
+  }
+}
@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    bitlaunch = {
+      version = "0.4.0"
+      source  = "pathtofile-tf/bitlaunch"
+    }
+  }
+}
+
+variable "token" { sensitive = true }
+
+provider "bitlaunch" {
+  token = var.token
+}
+
+# Every subregion in New York
+data "bitlaunch_regions" "example" {
+  host = "DigitalOcean"
+
+  filter {
+    name   = "region_name"
+    values = ["New York"]
+  }
+}
+
+output "new_york_regions" {
+  value = [for region in data.bitlaunch_regions.example.regions : region.id]
+}
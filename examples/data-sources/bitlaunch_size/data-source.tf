@@ -18,3 +18,12 @@ data "bitlaunch_size" "example" {
   cpu_count = 2
   memory_mb = 2048
 }
+
+# The cheapest size with at least 2 vCPUs and 4GB of memory in nyc1
+data "bitlaunch_size" "cheapest" {
+  host          = "DigitalOcean"
+  min_cpu_count = 2
+  min_memory_mb = 4096
+  region_id     = "nyc1"
+  prefer        = "cheapest"
+}
@@ -0,0 +1,33 @@
+terraform {
+  required_providers {
+    bitlaunch = {
+      version = "0.4.0"
+      source  = "pathtofile-tf/bitlaunch"
+    }
+  }
+}
+
+variable "token" { sensitive = true }
+
+provider "bitlaunch" {
+  token = var.token
+}
+
+# The cheapest sizes with at least 2 vCPUs
+data "bitlaunch_sizes" "example" {
+  host = "DigitalOcean"
+
+  filter {
+    name     = "cpu_count"
+    values   = ["2"]
+    match_by = "greater_than_or_equal"
+  }
+
+  sort {
+    key = "cost_per_hour"
+  }
+}
+
+output "cheapest_size" {
+  value = data.bitlaunch_sizes.example.sizes[0].id
+}
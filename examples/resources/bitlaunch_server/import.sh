@@ -0,0 +1,2 @@
+# Servers can be imported using their ID
+terraform import bitlaunch_server.server 5f1e2d3c4b5a69788796a5b4
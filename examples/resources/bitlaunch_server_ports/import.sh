@@ -0,0 +1,2 @@
+# Server ports can be imported using the ID of the server
+terraform import bitlaunch_server_ports.ports 5f1e2d3c4b5a69788796a5b4
@@ -0,0 +1,38 @@
+terraform {
+  required_providers {
+    bitlaunch = {
+      version = "0.4.0"
+      source  = "pathtofile-tf/bitlaunch"
+    }
+  }
+}
+
+variable "token" { sensitive = true }
+
+provider "bitlaunch" {
+  token = var.token
+}
+
+resource "bitlaunch_server" "server" {
+  host        = "BitLaunch"
+  name        = "tf_server"
+  image_id    = "10000"
+  size_id     = "nibble-1024"
+  region_id   = "lon1"
+  wait_for_ip = true
+}
+
+resource "bitlaunch_server_ports" "ports" {
+  server_id = bitlaunch_server.server.id
+
+  port {
+    protocol  = "tcp"
+    from_port = 22
+  }
+
+  port {
+    protocol  = "tcp"
+    from_port = 80
+    to_port   = 443
+  }
+}
@@ -0,0 +1,6 @@
+# SSH keys can be imported using their ID
+terraform import bitlaunch_sshkey.sshkey 5f1e2d3c4b5a69788796a5b4
+
+# Or by name or fingerprint, as long as only one key matches
+terraform import bitlaunch_sshkey.sshkey name:tf_sshkeys
+terraform import bitlaunch_sshkey.sshkey fingerprint:SHA256:Y/o4ZfIJNv6kCwJ7+eNmZ3nqfsC+bIpRxlnMNfNVBfQ
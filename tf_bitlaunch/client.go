@@ -0,0 +1,511 @@
+package tf_bitlaunch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/go-cleanhttp"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// Same as gobitlaunch
+	defaultAPIURL = "https://app.bitlaunch.io/api"
+	userAgent     = "terraform-provider-bitlaunch"
+
+	defaultMaxRetries   = 3
+	defaultRetryMaxWait = "30s"
+	retryMinWait        = 300 * time.Millisecond
+
+	defaultCreateOptionsCacheTTL = "10m"
+)
+
+// bitlaunchClient has the same services as gobitlaunch.Client and uses its types,
+// but lets us choose the base URL and HTTP client, and keeps the status code of
+// failed requests so we can tell when something doesn't exist. gobitlaunch.Client
+// fixes its base URL and keeps its retrying HTTP client to itself, so it can't be
+// used with the provider's endpoint, TLS, proxy, retry or rate limit settings.
+//
+// Only requests gobitlaunch v1.1.0 makes are sent, with the same paths, bodies and
+// response shapes, so each service method here matches the gobitlaunch one of the
+// same name. The one addition is reading hostOptions from the create options,
+// which the API returns (see host_create_example.json) and gobitlaunch has a type
+// for but doesn't decode.
+type bitlaunchClient struct {
+	baseURL string
+	token   string
+	hclient *http.Client
+	retry   retryPolicy
+
+	Account       *accountService
+	Server        *serverService
+	CreateOptions *createOptionsService
+	SSHKey        *sshKeyService
+}
+
+// apiError is returned for any response that isn't a 200
+type apiError struct {
+	StatusCode int
+	Body       string
+	RetryAfter string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("error %d %s", e.StatusCode, e.Body)
+}
+
+// isNotFound checks if an error from the API is a 404
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// isRetryableError is for failed requests that may work if sent again
+func isRetryableError(err error) bool {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	// Anything else is a network error, where we don't know if the request got there
+	return err != nil && isRetryableNetworkError(err)
+}
+
+// httpClientConfig is how the provider wants to talk to the API
+type httpClientConfig struct {
+	Timeout            time.Duration
+	InsecureSkipVerify bool
+	CAFile             string
+	ProxyURL           string
+}
+
+// newHTTPClient creates the http.Client used by bitlaunchClient
+func newHTTPClient(config httpClientConfig) (*http.Client, error) {
+	transport := cleanhttp.DefaultPooledTransport()
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	// net/http handles both HTTP and SOCKS5 proxies
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+	}, nil
+}
+
+// newBitlaunchClient creates a client for the API at baseURL, using httpClient if not nil.
+// Idempotent requests are retried by the transport according to retry, and if limiter
+// isn't nil, every request waits for it. httpClient's timeout applies to each attempt.
+func newBitlaunchClient(token string, baseURL string, httpClient *http.Client, retry retryPolicy, limiter *rate.Limiter) *bitlaunchClient {
+	hclient := cleanhttp.DefaultPooledClient()
+	if httpClient != nil {
+		copied := *httpClient
+		hclient = &copied
+	}
+	next := hclient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if hclient.Timeout > 0 {
+		next = &timeoutTransport{next: next, timeout: hclient.Timeout}
+		hclient.Timeout = 0
+	}
+	if limiter != nil {
+		next = &rateLimitTransport{next: next, limiter: limiter}
+	}
+	hclient.Transport = &retryTransport{next: next, policy: retry}
+
+	c := bitlaunchClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		hclient: hclient,
+		retry:   retry,
+	}
+
+	c.Account = &accountService{&c}
+	c.Server = &serverService{&c}
+	c.CreateOptions = &createOptionsService{client: &c}
+	c.SSHKey = &sshKeyService{&c}
+
+	return &c
+}
+
+// NewRequest creates an API Request
+func (c *bitlaunchClient) NewRequest(method, path string, body []byte) (*http.Request, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Authorization", "Bearer: "+c.token)
+	req.Header.Add("User-Agent", userAgent)
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// DoRequest performs a http request, decoding the response into data
+func (c *bitlaunchClient) DoRequest(r *http.Request, data interface{}) error {
+	res, err := c.hclient.Do(r)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return &apiError{
+			StatusCode: res.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+			RetryAfter: res.Header.Get("Retry-After"),
+		}
+	}
+	if data != nil {
+		if err := json.Unmarshal(body, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do builds and performs a request, marshalling in if not nil
+func (c *bitlaunchClient) do(method, path string, in interface{}, out interface{}) error {
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+
+	req, err := c.NewRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	return c.DoRequest(req, out)
+}
+
+type accountService struct {
+	client *bitlaunchClient
+}
+
+// Show the account
+func (as *accountService) Show() (*gobitlaunch.Account, error) {
+	account := gobitlaunch.Account{}
+	if err := as.client.do("GET", "/user", nil, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// usageEntry is a charge in accountUsage
+type usageEntry struct {
+	Description string    `json:"description"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Cost        int       `json:"cost"`
+	Hours       int       `json:"hours"`
+	Amount      int       `json:"amount"`
+	Type        string    `json:"type"`
+}
+
+// accountUsage is the same as gobitlaunch.AccountUsage, which doesn't export the type of its entries
+type accountUsage struct {
+	Server     []usageEntry `json:"serverUsage"`
+	Backup     []usageEntry `json:"backupUsage"`
+	Bandwidth  []usageEntry `json:"bandwidthUsage"`
+	Protection []usageEntry `json:"protectionUsage"`
+	TotalUSD   int          `json:"totalUsd"`
+	PrevMonth  string       `json:"prevMonth"`
+	ThisMonth  string       `json:"thisMonth"`
+	NextMonth  string       `json:"nextMonth"`
+}
+
+// Usage shows the account usage for a period, either "latest" or a month like "2022-01"
+func (as *accountService) Usage(period string) (*accountUsage, error) {
+	usage := accountUsage{}
+	if err := as.client.do("GET", "/usage?period="+url.QueryEscape(period), nil, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+type serverService struct {
+	client *bitlaunchClient
+}
+
+// Create server. This isn't safe to blindly retry, so after a failed attempt it first
+// checks if the server was created anyway. Cancelling ctx stops it waiting to retry.
+func (ss *serverService) Create(ctx context.Context, opts *gobitlaunch.CreateServerOptions) (*gobitlaunch.Server, error) {
+	started := time.Now()
+	in := map[string]*gobitlaunch.CreateServerOptions{"server": opts}
+	for attempt := 0; ; attempt++ {
+		s := gobitlaunch.Server{}
+		err := ss.client.do("POST", "/servers", in, &s)
+		if err == nil {
+			return &s, nil
+		}
+		if attempt >= ss.client.retry.MaxRetries || !isRetryableError(err) {
+			return nil, err
+		}
+
+		existing, listErr := ss.findCreated(opts, started)
+		if listErr != nil {
+			// Can't tell if it was created, so don't risk a duplicate
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+
+		var retryAfter string
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			retryAfter = apiErr.RetryAfter
+		}
+		if err := sleepContext(ctx, ss.client.retry.backoff(attempt, retryAfter)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// findCreated finds a server matching opts that was created since started
+func (ss *serverService) findCreated(opts *gobitlaunch.CreateServerOptions, started time.Time) (*gobitlaunch.Server, error) {
+	servers, err := ss.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		// Allow for some clock difference with the API
+		if server.Created.Before(started.Add(-1 * time.Minute)) {
+			continue
+		}
+		if server.Name == opts.Name && server.HostID == opts.HostID && server.Size == opts.SizeID && server.Region == opts.RegionID {
+			return &server, nil
+		}
+	}
+	return nil, nil
+}
+
+// Show server
+func (ss *serverService) Show(id string) (*gobitlaunch.Server, error) {
+	s := struct {
+		Server gobitlaunch.Server
+	}{}
+	if err := ss.client.do("GET", "/servers/"+id, nil, &s); err != nil {
+		return nil, err
+	}
+	return &s.Server, nil
+}
+
+// List servers
+func (ss *serverService) List() ([]gobitlaunch.Server, error) {
+	servers := []gobitlaunch.Server{}
+	if err := ss.client.do("GET", "/servers", nil, &servers); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// Destroy a server
+func (ss *serverService) Destroy(id string) error {
+	return ss.client.do("DELETE", "/servers/"+id, nil, nil)
+}
+
+// Rebuild server
+func (ss *serverService) Rebuild(id string, opts *gobitlaunch.RebuildOptions) error {
+	return ss.client.do("POST", "/servers/"+id+"/rebuild", opts, nil)
+}
+
+// Resize server
+func (ss *serverService) Resize(id string, size string) error {
+	return ss.client.do("POST", "/servers/"+id+"/resize", map[string]string{"size": size}, nil)
+}
+
+// Restart server
+func (ss *serverService) Restart(id string) error {
+	return ss.client.do("POST", "/servers/"+id+"/restart", nil, nil)
+}
+
+// Protection turns DDoS protection on or off. The region is where the protection
+// proxy runs, and is only used when turning it on. gobitlaunch always uses bvm-lux.
+func (ss *serverService) Protection(id string, enabled bool, region string) (*gobitlaunch.Server, error) {
+	in := struct {
+		Enabled bool   `json:"enable"`
+		Region  string `json:"region"`
+	}{Enabled: enabled}
+	if enabled {
+		in.Region = region
+	}
+	s := gobitlaunch.Server{}
+	if err := ss.client.do("POST", "/servers/"+id+"/protection", in, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SetPorts sets the ports the protection proxy lets through. Protection has to be on.
+func (ss *serverService) SetPorts(id string, ports []gobitlaunch.Ports) (*gobitlaunch.Server, error) {
+	s := gobitlaunch.Server{}
+	if err := ss.client.do("POST", "/servers/"+id+"/protection/ports", ports, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// hostCreateOptions adds what a host supports (rebuild, resize, etc.) to
+// gobitlaunch.ServerCreateOptions, which doesn't decode it
+type hostCreateOptions struct {
+	gobitlaunch.ServerCreateOptions
+	HostOptions gobitlaunch.HostOptions `json:"hostOptions"`
+}
+
+// createOptionsService caches the create options for cacheTTL, as they're large and
+// every image, size and region lookup needs them. Parallel calls for the same host
+// share one request.
+type createOptionsService struct {
+	client   *bitlaunchClient
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[int]cachedCreateOptions
+	group singleflight.Group
+}
+
+type cachedCreateOptions struct {
+	options *hostCreateOptions
+	fetched time.Time
+}
+
+// Show the server create options. The result may be shared, so mustn't be changed.
+func (co *createOptionsService) Show(hostID int) (*hostCreateOptions, error) {
+	if co.cacheTTL <= 0 {
+		return co.fetch(hostID)
+	}
+
+	if ops, ok := co.cached(hostID); ok {
+		return ops, nil
+	}
+
+	v, err, _ := co.group.Do(strconv.Itoa(hostID), func() (interface{}, error) {
+		// Another call may have filled the cache since we checked
+		if ops, ok := co.cached(hostID); ok {
+			return ops, nil
+		}
+		ops, err := co.fetch(hostID)
+		if err != nil {
+			return nil, err
+		}
+		co.mu.Lock()
+		if co.cache == nil {
+			co.cache = map[int]cachedCreateOptions{}
+		}
+		co.cache[hostID] = cachedCreateOptions{options: ops, fetched: time.Now()}
+		co.mu.Unlock()
+		return ops, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*hostCreateOptions), nil
+}
+
+// cached gets the create options for a host if they're in the cache and haven't expired
+func (co *createOptionsService) cached(hostID int) (*hostCreateOptions, bool) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	cached, ok := co.cache[hostID]
+	if !ok || time.Since(cached.fetched) >= co.cacheTTL {
+		return nil, false
+	}
+	return cached.options, true
+}
+
+// fetch gets the create options from the API, skipping the cache
+func (co *createOptionsService) fetch(hostID int) (*hostCreateOptions, error) {
+	s := hostCreateOptions{}
+	if err := co.client.do("GET", "/hosts-create-options/"+strconv.Itoa(hostID), nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+type sshKeyService struct {
+	client *bitlaunchClient
+}
+
+// Create ssh key
+func (ss *sshKeyService) Create(k *gobitlaunch.SSHKey) (*gobitlaunch.SSHKey, error) {
+	s := gobitlaunch.SSHKey{}
+	if err := ss.client.do("POST", "/ssh-keys", k, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List ssh keys
+func (ss *sshKeyService) List() ([]gobitlaunch.SSHKey, error) {
+	s := struct {
+		Keys []gobitlaunch.SSHKey
+	}{}
+	if err := ss.client.do("GET", "/ssh-keys", nil, &s); err != nil {
+		return nil, err
+	}
+	return s.Keys, nil
+}
+
+// Delete an SSH Key
+func (ss *sshKeyService) Delete(id string) error {
+	return ss.client.do("DELETE", "/ssh-keys/"+id, nil, nil)
+}
@@ -0,0 +1,169 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testServer is a server that can be added to the mock API
+func testServer() gobitlaunch.Server {
+	return gobitlaunch.Server{Name: "web", HostID: 4, Image: "10000", Size: "nibble-1024", Region: "lon1", Status: "ok"}
+}
+
+func testCreateServerOptions() *gobitlaunch.CreateServerOptions {
+	return &gobitlaunch.CreateServerOptions{Name: "web", HostID: 4, HostImageID: "10000", SizeID: "nibble-1024", RegionID: "lon1"}
+}
+
+func TestServerCreateRetry(t *testing.T) {
+	api := newMockAPI(t)
+	client := newBitlaunchClient(mockToken, api.URL, api.Server.Client(), testRetryPolicy, nil)
+
+	// The first attempt failed before creating anything, so try again
+	api.failNext("POST /servers", mockFailure{StatusCode: http.StatusServiceUnavailable})
+	server, err := client.Server.Create(context.Background(), testCreateServerOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls := api.callCount("POST /servers"); calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+	if err := client.Server.Destroy(server.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first attempt created the server but still failed, so use that one
+	api.failNext("POST /servers", mockFailure{StatusCode: http.StatusGatewayTimeout, Handled: true})
+	server, err = client.Server.Create(context.Background(), testCreateServerOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls := api.callCount("POST /servers"); calls != 3 {
+		t.Errorf("expected no more attempts after finding the server, got %d in total", calls)
+	}
+	servers, _ := client.Server.List()
+	if len(servers) != 1 || servers[0].ID != server.ID {
+		t.Errorf("expected only the first server to exist, got %v", servers)
+	}
+
+	// Errors that won't go away aren't retried
+	opts := testCreateServerOptions()
+	opts.SizeID = "missing"
+	if _, err := client.Server.Create(context.Background(), opts); err == nil {
+		t.Errorf("expected an error for a missing size")
+	}
+	if calls := api.callCount("POST /servers"); calls != 4 {
+		t.Errorf("expected 1 more attempt, got %d in total", calls)
+	}
+
+	// Cancelling stops the wait to retry
+	client.retry.MinWait = time.Minute
+	client.retry.MaxWait = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	api.failNext("POST /servers", mockFailure{StatusCode: http.StatusServiceUnavailable})
+	opts = testCreateServerOptions()
+	opts.Name = "db"
+	started := time.Now()
+	if _, err := client.Server.Create(ctx, opts); err == nil {
+		t.Errorf("expected an error once cancelled")
+	}
+	if time.Since(started) > 5*time.Second {
+		t.Errorf("expected cancelling to stop the wait")
+	}
+}
+
+func TestCreateOptionsCache(t *testing.T) {
+	api := newMockAPI(t)
+	client := newBitlaunchClient(mockToken, api.URL, api.Server.Client(), retryPolicy{}, nil)
+	client.CreateOptions.cacheTTL = time.Minute
+
+	// Parallel calls share one request
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.CreateOptions.Show(4); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := api.callCount("GET /hosts-create-options/4"); calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+
+	// Each host is cached separately
+	if _, err := client.CreateOptions.Show(0); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls := api.callCount("GET /hosts-create-options/0"); calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+
+	// Errors aren't cached
+	api.failNext("GET /hosts-create-options/1", mockFailure{StatusCode: http.StatusBadRequest})
+	client.CreateOptions.Show(1)
+	client.CreateOptions.Show(1)
+	if calls := api.callCount("GET /hosts-create-options/1"); calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	// Expired options are fetched again
+	client.CreateOptions.cacheTTL = time.Nanosecond
+	if _, err := client.CreateOptions.Show(4); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls := api.callCount("GET /hosts-create-options/4"); calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	// No TTL turns the cache off
+	client.CreateOptions.cacheTTL = 0
+	client.CreateOptions.Show(4)
+	client.CreateOptions.Show(4)
+	if calls := api.callCount("GET /hosts-create-options/4"); calls != 4 {
+		t.Errorf("expected 4 calls, got %d", calls)
+	}
+}
+
+func TestCreateOptionsCacheDataSources(t *testing.T) {
+	api := newMockAPI(t)
+	meta := testConfigureProvider(t, map[string]interface{}{})
+
+	reads := []struct {
+		resource *schema.Resource
+		config   map[string]interface{}
+	}{
+		{dataSourceImage(), map[string]interface{}{"host": "BitLaunch", "distro_name": "Ubuntu"}},
+		{dataSourceSize(), map[string]interface{}{"host": "BitLaunch", "cpu_count": 2}},
+		{dataSourceRegion(), map[string]interface{}{"host": "bitlaunch", "region_name": "Amsterdam"}},
+		{dataSourceHosts(), map[string]interface{}{}},
+	}
+	for _, read := range reads {
+		data := schema.TestResourceDataRaw(t, read.resource.Schema, read.config)
+		if diags := read.resource.ReadContext(context.Background(), data, meta); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+	}
+	if calls := api.callCount("GET /hosts-create-options/4"); calls != 1 {
+		t.Errorf("expected the data sources to share 1 call, got %d", calls)
+	}
+
+	meta = testConfigureProvider(t, map[string]interface{}{"create_options_cache_ttl": "0s"})
+	for _, read := range reads[:3] {
+		data := schema.TestResourceDataRaw(t, read.resource.Schema, read.config)
+		if diags := read.resource.ReadContext(context.Background(), data, meta); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+	}
+	if calls := api.callCount("GET /hosts-create-options/4"); calls != 4 {
+		t.Errorf("expected 3 more calls with the cache off, got %d in total", calls)
+	}
+}
@@ -0,0 +1,248 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// usageSchema is a list of usage entries in the account usage
+func usageSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Description: description,
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"description": {
+					Description: "What was used.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"type": {
+					Description: "The type of usage.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"start": {
+					Description: "When the usage started.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"end": {
+					Description: "When the usage ended.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"hours": {
+					Description: "How many hours were used.",
+					Type:        schema.TypeInt,
+					Computed:    true,
+				},
+				"cost": {
+					Description: "The cost per hour.",
+					Type:        schema.TypeInt,
+					Computed:    true,
+				},
+				"amount": {
+					Description: "The total amount charged.",
+					Type:        schema.TypeInt,
+					Computed:    true,
+				},
+			},
+		},
+	}
+}
+
+// https://developers.bitlaunch.io/reference/get-account
+func dataSourceAccount() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Holds details on the BitLaunch account the provider is using, such as its balance and usage. Matches https://developers.bitlaunch.io/reference/get-account",
+
+		ReadContext: dataSourceAccountRead,
+
+		Schema: map[string]*schema.Schema{
+			"usage_period": {
+				Description:  "The month to get the usage of, like `2022-01`, or `latest` for the current month.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "latest",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^(latest|\d{4}-\d{2})$`), "must be latest or a month like 2022-01"),
+			},
+			"email": {
+				Description: "The email address of the account.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"email_confirmed": {
+				Description: "If the email address has been confirmed.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"created": {
+				Description: "The creation date of the account.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"two_factor_enabled": {
+				Description: "If two factor authentication is enabled.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"balance": {
+				Description: "The balance of the account, in the same units as server rates.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"cost_per_hour": {
+				Description: "The amount of balance deducted per hour for every server on the account.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"negative_allowance": {
+				Description: "How far below zero the balance can go before servers are stopped.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"low_balance_alert_days": {
+				Description: "How many days of balance are left when a low balance alert is sent (`billingAlert`).",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"used": {
+				Description: "The number of servers on the account.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"limit": {
+				Description: "The most servers the account can have.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"usage": {
+				Description: "What the account was charged for in `usage_period`.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"total_usd": {
+							Description: "The total charged in USD.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"this_month": {
+							Description: "The month the usage is for.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"prev_month": {
+							Description: "The month before, to use as `usage_period`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"next_month": {
+							Description: "The month after, to use as `usage_period`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"server":     usageSchema("Charges for servers."),
+						"backup":     usageSchema("Charges for backups."),
+						"bandwidth":  usageSchema("Charges for bandwidth."),
+						"protection": usageSchema("Charges for DDoS protection."),
+					},
+				},
+			},
+		},
+	}
+}
+
+func flattenUsage(entries []usageEntry) []interface{} {
+	flattened := []interface{}{}
+	for _, entry := range entries {
+		flattened = append(flattened, map[string]interface{}{
+			"description": entry.Description,
+			"type":        entry.Type,
+			"start":       entry.Start.Format(time.RFC3339),
+			"end":         entry.End.Format(time.RFC3339),
+			"hours":       entry.Hours,
+			"cost":        entry.Cost,
+			"amount":      entry.Amount,
+		})
+	}
+	return flattened
+}
+
+func setDataAccount(data *schema.ResourceData, account *gobitlaunch.Account, usage *accountUsage) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	data.SetId(account.ID)
+	if err := data.Set("email", account.Email); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("email_confirmed", account.EmailConfirmed); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("created", account.Created.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("two_factor_enabled", account.Twofa); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("balance", account.Balance); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("cost_per_hour", account.CostPerHr); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("negative_allowance", account.NegativeAllowance); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("low_balance_alert_days", account.LowBalanceAlertDays); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("used", account.Used); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := data.Set("limit", account.Limit); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tfUsage := map[string]interface{}{
+		"total_usd":  usage.TotalUSD,
+		"this_month": usage.ThisMonth,
+		"prev_month": usage.PrevMonth,
+		"next_month": usage.NextMonth,
+		"server":     flattenUsage(usage.Server),
+		"backup":     flattenUsage(usage.Backup),
+		"bandwidth":  flattenUsage(usage.Bandwidth),
+		"protection": flattenUsage(usage.Protection),
+	}
+	if err := data.Set("usage", []interface{}{tfUsage}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func dataSourceAccountRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Getting the Account")
+
+	account, err := client.Account.Show()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	usage, err := client.Account.Usage(data.Get("usage_period").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return setDataAccount(data, account, usage)
+}
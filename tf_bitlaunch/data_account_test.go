@@ -0,0 +1,67 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccdataSourceAccount(t *testing.T) {
+	api := newMockAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: api.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccdataSourceAccount,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.bitlaunch_account.account", "email", "mock@example.com"),
+					resource.TestCheckResourceAttr("data.bitlaunch_account.account", "balance", "100000"),
+					resource.TestCheckResourceAttr("data.bitlaunch_account.account", "usage.0.this_month", "2022-01"),
+					resource.TestCheckResourceAttr("data.bitlaunch_account.account", "usage.0.prev_month", "2021-12"),
+				),
+			},
+		},
+	})
+}
+
+const testAccdataSourceAccount = `
+data "bitlaunch_account" "account" {
+  usage_period = "2022-01"
+}
+`
+
+func TestDataSourceAccountRead(t *testing.T) {
+	api := newMockAPI(t)
+	server := testServer()
+	server.Rate = 14
+	server.Created = time.Now().UTC()
+	api.addServer(server)
+
+	data := schema.TestResourceDataRaw(t, dataSourceAccount().Schema, map[string]interface{}{})
+	if diags := dataSourceAccountRead(context.Background(), data, api.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if data.Id() != "mock-account" {
+		t.Errorf("expected the account ID, got %q", data.Id())
+	}
+	if cost := data.Get("cost_per_hour").(int); cost != 14 {
+		t.Errorf("expected cost_per_hour 14, got %d", cost)
+	}
+	if used := data.Get("used").(int); used != 1 {
+		t.Errorf("expected 1 server used, got %d", used)
+	}
+	if month := data.Get("usage.0.this_month").(string); month != time.Now().UTC().Format("2006-01") {
+		t.Errorf("expected the latest usage, got %q", month)
+	}
+	if description := data.Get("usage.0.server.0.description").(string); description != server.Name {
+		t.Errorf("expected usage for the server, got %q", description)
+	}
+	if calls := api.callCount("GET /usage"); calls != 1 {
+		t.Errorf("expected 1 usage call, got %d", calls)
+	}
+}
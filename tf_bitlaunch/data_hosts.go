@@ -0,0 +1,118 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceHosts() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Lists the hosts servers can be created on, and what each of them supports. Matches https://developers.bitlaunch.io/reference/view-host-create-options",
+
+		ReadContext: dataSourceHostsRead,
+
+		Schema: map[string]*schema.Schema{
+			"host_ids": {
+				Description: "Extra host IDs to look up, as well as the hosts the provider knows the names of.",
+				Type:        schema.TypeList,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				Optional: true,
+			},
+			"hosts": {
+				Description: "The hosts that are available. Hosts the API doesn't have create options for are left out.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "The ID of the host.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"name": {
+							Description: "The name of the host, or its ID if the provider doesn't know the name.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"rebuild": {
+							Description: "Whether servers on this host can be rebuilt.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						"resize": {
+							Description: "Whether servers on this host can be resized.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						"backups": {
+							Description: "Whether servers on this host can have backups.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						"user_script": {
+							Description: "Whether servers on this host can run an initscript.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceHostsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Getting Hosts")
+
+	hostIDs := maps.Values(HostIDs)
+	for _, id := range data.Get("host_ids").([]interface{}) {
+		if !slices.Contains(hostIDs, id.(int)) {
+			hostIDs = append(hostIDs, id.(int))
+		}
+	}
+	slices.Sort(hostIDs)
+
+	hosts := []map[string]interface{}{}
+	ids := []string{}
+	for _, hostID := range hostIDs {
+		ops, err := client.CreateOptions.Show(hostID)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		hosts = append(hosts, map[string]interface{}{
+			"id":          hostID,
+			"name":        hostNameFromID(hostID),
+			"rebuild":     ops.HostOptions.Rebuild,
+			"resize":      ops.HostOptions.Resize,
+			"backups":     ops.HostOptions.Backups,
+			"user_script": ops.HostOptions.Userscript,
+		})
+		ids = append(ids, strconv.Itoa(hostID))
+	}
+
+	data.SetId(strings.Join(ids, ","))
+	if err := data.Set("hosts", hosts); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
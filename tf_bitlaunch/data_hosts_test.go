@@ -0,0 +1,64 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccdataSourceHosts(t *testing.T) {
+	api := newMockAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: api.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccdataSourceHosts,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.bitlaunch_hosts.hosts", "hosts.#", "2"),
+					resource.TestCheckResourceAttr("data.bitlaunch_hosts.hosts", "hosts.1.name", "BitLaunch"),
+					resource.TestCheckResourceAttr("data.bitlaunch_hosts.hosts", "hosts.1.rebuild", "true"),
+				),
+			},
+		},
+	})
+}
+
+const testAccdataSourceHosts = `
+data "bitlaunch_hosts" "hosts" {}
+`
+
+func TestDataSourceHostsRead(t *testing.T) {
+	api := newMockAPI(t)
+	// A host the provider doesn't know the name of
+	api.createOptions[7] = api.createOptions[4]
+
+	data := schema.TestResourceDataRaw(t, dataSourceHosts().Schema, map[string]interface{}{
+		"host_ids": []interface{}{7, 4},
+	})
+	if diags := dataSourceHostsRead(context.Background(), data, api.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// Vultr and Linode aren't in the mock, so they're left out
+	hosts := data.Get("hosts").([]interface{})
+	names := []string{}
+	for _, host := range hosts {
+		names = append(names, host.(map[string]interface{})["name"].(string))
+	}
+	want := []string{"DigitalOcean", "BitLaunch", "7"}
+	if len(names) != len(want) {
+		t.Fatalf("got hosts %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("got hosts %v, want %v", names, want)
+		}
+	}
+	if data.Id() != "0,4,7" {
+		t.Errorf("got ID %q", data.Id())
+	}
+}
@@ -2,13 +2,21 @@ package tf_bitlaunch
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/bitlaunchio/gobitlaunch"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// imageMatchKeys are the arguments that choose an image, at least one of which is needed
+var imageMatchKeys = []string{"distro_name", "distro_name_regex", "version_name", "version_regex", "type"}
+
 func dataSourceImage() *schema.Resource {
 	return &schema.Resource{
 		// This description is used by the documentation generator and the language server.
@@ -18,27 +26,56 @@ func dataSourceImage() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"host": {
-				Description:  "Host Provider (DigitalOcean, Vultr, etc.)",
+				Description:  "Host Provider (DigitalOcean, Vultr, etc.), by name or numeric ID.",
 				Type:         schema.TypeString,
 				Required:     true,
 				ValidateFunc: ValidateHostID,
 			},
 			"distro_name": {
-				Description:  "The name of the Linux Distibution or one-click app.",
-				Type:         schema.TypeString,
-				Optional:     true,
-				AtLeastOneOf: []string{"distro_name", "version_name"},
+				Description:   "The name of the Linux Distibution or one-click app.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				AtLeastOneOf:  imageMatchKeys,
+				ConflictsWith: []string{"distro_name_regex"},
+			},
+			"distro_name_regex": {
+				Description:   "A regex the name of the Linux Distibution or one-click app has to match, instead of `distro_name`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validation.StringIsValidRegExp,
+				AtLeastOneOf:  imageMatchKeys,
+				ConflictsWith: []string{"distro_name"},
 			},
 			"version_name": {
-				Description:  "The Specific Image Version",
-				Type:         schema.TypeString,
-				Optional:     true,
-				AtLeastOneOf: []string{"distro_name", "version_name"},
+				Description:   "The Specific Image Version",
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				AtLeastOneOf:  imageMatchKeys,
+				ConflictsWith: []string{"version_regex"},
+			},
+			"version_regex": {
+				Description:   "A regex the image version has to match, instead of `version_name`.",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validation.StringIsValidRegExp,
+				AtLeastOneOf:  imageMatchKeys,
+				ConflictsWith: []string{"version_name"},
+			},
+			"most_recent": {
+				Description: "Pick the highest version number out of every matching version, instead of the first match. Without `version_name` or `version_regex`, the first match is the default version of the first matching image.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
 			},
 			"type": {
-				Description: "The type of the image: image or app.",
-				Type:        schema.TypeString,
-				Computed:    true,
+				Description:  "The type of the image: image or app. If set, only images of this type match.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{"image", "app"}, false),
+				AtLeastOneOf: imageMatchKeys,
 			},
 			"min_disk_size": {
 				Description: "The minimum disk size available in GB.",
@@ -66,6 +103,30 @@ func dataSourceImage() *schema.Resource {
 				Type:        schema.TypeBool,
 				Computed:    true,
 			},
+			"versions": {
+				Description: "Every version of the chosen Linux Distibution or one-click app.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "The ID of the version, to use as a server's `image_id`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"description": {
+							Description: "The name of the version.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"password_unsupported": {
+							Description: "If setting a password is supported.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -102,46 +163,152 @@ func setDataImage(data *schema.ResourceData, image *gobitlaunch.HostImage, versi
 		return diag.FromErr(err)
 	}
 
+	versions := []map[string]interface{}{}
+	for _, v := range imageVersions(image) {
+		versions = append(versions, map[string]interface{}{
+			"id":                   v.ID,
+			"description":          v.Description,
+			"password_unsupported": v.PasswordUnsupported,
+		})
+	}
+	if err := data.Set("versions", versions); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return diags
 }
 
+// findImageVersion finds an image version by ID, in both default and other versions, and the image it belongs to
+func findImageVersion(ops *gobitlaunch.ServerCreateOptions, versionID string) (*gobitlaunch.HostImage, *gobitlaunch.HostImageVersion) {
+	for i, image := range ops.Images {
+		if image.DefaultVersion.ID == versionID {
+			return &ops.Images[i], &ops.Images[i].DefaultVersion
+		}
+		for j, version := range image.Versions {
+			if version.ID == versionID {
+				return &ops.Images[i], &ops.Images[i].Versions[j]
+			}
+		}
+	}
+	return nil, nil
+}
+
 func dataSourceImageRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	var diags diag.Diagnostics
 	client := meta.(*apiClient).client
 	tflog.Trace(ctx, "Getting a Image")
 
 	hostName := data.Get("host").(string)
-	hostID := HostIDs[hostName]
+	hostID, err := resolveHostID(hostName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	ops, err := client.CreateOptions.Show(hostID)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	name := data.Get("distro_name").(string)
-	versionName := data.Get("version_name").(string)
+	image, version, err := selectImage(ops, imageConstraints{
+		Name:         data.Get("distro_name").(string),
+		NameRegex:    data.Get("distro_name_regex").(string),
+		Version:      data.Get("version_name").(string),
+		VersionRegex: data.Get("version_regex").(string),
+		Type:         data.Get("type").(string),
+		MostRecent:   data.Get("most_recent").(bool),
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return setDataImage(data, image, version, hostName)
+}
 
-	if len(name) == 0 && len(versionName) == 0 {
-		return diag.Errorf("Require one of distro_name, version_name")
+// imageConstraints are what an image version has to match. Empty values aren't checked.
+type imageConstraints struct {
+	Name         string
+	NameRegex    string
+	Version      string
+	VersionRegex string
+	Type         string
+	MostRecent   bool
+}
+
+var versionNumberRegex = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// parseVersionNumber gets the first version number from a description, like [20 4] from
+// "20.04 (LTS) x64". It's nil if there isn't one.
+func parseVersionNumber(description string) []int {
+	match := versionNumberRegex.FindString(description)
+	if match == "" {
+		return nil
 	}
-	for _, image := range ops.Images {
-		if len(name) != 0 && image.Name != name {
-			continue
+	parts := []int{}
+	for _, part := range strings.Split(match, ".") {
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// compareVersionNumbers orders version numbers, with no version number lowest
+func compareVersionNumbers(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
 		}
+	}
+	return len(a) - len(b)
+}
 
-		if len(versionName) == 0 || image.DefaultVersion.Description == versionName {
-			// Get first/default version
-			setDataImage(data, &image, &image.DefaultVersion, hostName)
-			return diags
+// selectImage picks the first image version that matches, or the highest version if MostRecent
+func selectImage(ops *hostCreateOptions, constraints imageConstraints) (*gobitlaunch.HostImage, *gobitlaunch.HostImageVersion, error) {
+	var nameRegex, versionRegex *regexp.Regexp
+	var err error
+	if constraints.NameRegex != "" {
+		if nameRegex, err = regexp.Compile(constraints.NameRegex); err != nil {
+			return nil, nil, err
 		}
+	}
+	if constraints.VersionRegex != "" {
+		if versionRegex, err = regexp.Compile(constraints.VersionRegex); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Without a version to match, only the default versions are used, unless looking for the most recent
+	allVersions := constraints.Version != "" || versionRegex != nil || constraints.MostRecent
 
-		// Otherwise look in versions
-		for _, version := range image.Versions {
-			if version.Description == versionName {
-				setDataImage(data, &image, &version, hostName)
-				return diags
+	var selectedImage *gobitlaunch.HostImage
+	var selectedVersion *gobitlaunch.HostImageVersion
+	for i := range ops.Images {
+		image := &ops.Images[i]
+		switch {
+		case constraints.Name != "" && image.Name != constraints.Name,
+			nameRegex != nil && !nameRegex.MatchString(image.Name),
+			constraints.Type != "" && image.Type != constraints.Type:
+			continue
+		}
+
+		versions := []gobitlaunch.HostImageVersion{image.DefaultVersion}
+		if allVersions {
+			versions = imageVersions(image)
+		}
+		for j := range versions {
+			version := &versions[j]
+			switch {
+			case constraints.Version != "" && version.Description != constraints.Version,
+				versionRegex != nil && !versionRegex.MatchString(version.Description):
+				continue
+			}
+			if !constraints.MostRecent {
+				return image, version, nil
+			}
+			if selectedVersion == nil || compareVersionNumbers(parseVersionNumber(version.Description), parseVersionNumber(selectedVersion.Description)) > 0 {
+				selectedImage, selectedVersion = image, version
 			}
 		}
 	}
 
-	return diag.Errorf("Can't find matching Image")
+	if selectedVersion == nil {
+		return nil, nil, errors.New("Can't find matching Image")
+	}
+	return selectedImage, selectedVersion, nil
 }
@@ -1,24 +1,26 @@
 package tf_bitlaunch
 
 import (
-	"regexp"
+	"context"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func TestAccdataSourceImage(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
+	api := newMockAPI(t)
 
-	resource.UnitTest(t, resource.TestCase{
+	resource.Test(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
+		ProviderFactories: api.providerFactories(),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccdataSourceImage,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.scaffolding_data_source.foo", "sample_attribute", regexp.MustCompile("^ba")),
+					resource.TestCheckResourceAttr("data.bitlaunch_image.image", "id", "10001"),
+					resource.TestCheckResourceAttr("data.bitlaunch_image.image", "type", "image"),
+					resource.TestCheckResourceAttr("data.bitlaunch_image.image", "min_disk_size", "20"),
 				),
 			},
 		},
@@ -26,7 +28,94 @@ func TestAccdataSourceImage(t *testing.T) {
 }
 
 const testAccdataSourceImage = `
-data "scaffolding_data_source" "foo" {
-  sample_attribute = "bar"
+data "bitlaunch_image" "image" {
+  host         = "BitLaunch"
+  distro_name  = "Ubuntu"
+  version_name = "Ubuntu 19.04"
 }
 `
+
+func TestDataSourceImageRead(t *testing.T) {
+	api := newMockAPI(t)
+
+	data := schema.TestResourceDataRaw(t, dataSourceImage().Schema, map[string]interface{}{
+		"host":        "DigitalOcean",
+		"distro_name": "Ubuntu",
+	})
+	if diags := dataSourceImageRead(context.Background(), data, api.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if data.Id() == "" {
+		t.Errorf("expected the default Ubuntu version to be found")
+	}
+	if count := data.Get("versions.#").(int); count != 3 {
+		t.Errorf("expected all 3 Ubuntu versions, got %d", count)
+	}
+
+	data = schema.TestResourceDataRaw(t, dataSourceImage().Schema, map[string]interface{}{
+		"host":        "BitLaunch",
+		"distro_name": "Missing",
+	})
+	if diags := dataSourceImageRead(context.Background(), data, api.client()); !diags.HasError() {
+		t.Errorf("expected an error for a missing image")
+	}
+}
+
+func TestSelectImage(t *testing.T) {
+	api := newMockAPI(t)
+	ops, err := api.client().client.CreateOptions.Show(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		constraints imageConstraints
+		want        string
+	}{
+		"default version":          {imageConstraints{Name: "Ubuntu"}, "106427349"},
+		"exact version":            {imageConstraints{Version: "20.04 (LTS) x64"}, "106421232"},
+		"version regex":            {imageConstraints{Name: "Ubuntu", VersionRegex: "LTS"}, "106421232"},
+		"most recent LTS":          {imageConstraints{NameRegex: "^Ubuntu$", VersionRegex: `\(LTS\)`, MostRecent: true}, "106421232"},
+		"most recent":              {imageConstraints{Name: "CentOS", MostRecent: true}, "106434098"},
+		"most recent across names": {imageConstraints{NameRegex: "^(Debian|CentOS)$", MostRecent: true}, "106557160"},
+		"type":                     {imageConstraints{Type: "app", NameRegex: "^Word"}, "92582848"},
+		"wrong type":               {imageConstraints{Type: "image", Name: "WordPress"}, ""},
+		"no match":                 {imageConstraints{Name: "Ubuntu", VersionRegex: "^99"}, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, version, err := selectImage(ops, test.constraints)
+			if test.want == "" {
+				if err == nil {
+					t.Errorf("expected an error, got %s", version.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if version.ID != test.want {
+				t.Errorf("got %s (%s), want %s", version.ID, version.Description, test.want)
+			}
+		})
+	}
+}
+
+func TestParseVersionNumber(t *testing.T) {
+	for description, want := range map[string][]int{
+		"21.10 x64":        {21, 10},
+		"Debian 11 x64":    {11},
+		"Ubuntu 18.04 LTS": {18, 4},
+		"Ubuntu":           nil,
+	} {
+		got := parseVersionNumber(description)
+		if compareVersionNumbers(got, want) != 0 || len(got) != len(want) {
+			t.Errorf("%s: got %v, want %v", description, got, want)
+		}
+	}
+	if compareVersionNumbers([]int{20, 4}, []int{18, 4}) <= 0 {
+		t.Errorf("expected 20.04 to be after 18.04")
+	}
+	if compareVersionNumbers(nil, []int{1}) >= 0 {
+		t.Errorf("expected no version to be lowest")
+	}
+}
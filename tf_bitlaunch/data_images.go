@@ -0,0 +1,154 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// imageItemSchema is the schema of each image version in bitlaunch_images
+func imageItemSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"id": {
+			Description: "The ID of the image version, to use as a server's `image_id`.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"distro_name": {
+			Description: "The name of the Linux Distibution or one-click app.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"version_name": {
+			Description: "The Specific Image Version",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"is_default": {
+			Description: "If this is the version used by default for the distribution or app.",
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+		"type": {
+			Description: "The type of the image: image or app.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"min_disk_size": {
+			Description: "The minimum disk size available in GB.",
+			Type:        schema.TypeInt,
+			Computed:    true,
+		},
+		"unavailable_regions": {
+			Description: "A list of unavailable subregion IDs.",
+			Type:        schema.TypeList,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Computed:    true,
+		},
+		"extra_cost_per_month": {
+			Description: "Extra monthly cost.",
+			Type:        schema.TypeInt,
+			Computed:    true,
+		},
+		"is_windows": {
+			Description: "Flag to determine if the image is Windows-based.",
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+		"password_unsupported": {
+			Description: "If setting a password is supported.",
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+	}
+}
+
+func dataSourceImages() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Lists every version of every image and app available on a host, optionally filtered and sorted. Matches https://developers.bitlaunch.io/reference/host-image-object",
+
+		ReadContext: dataSourceImagesRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Description:  "Host Provider (DigitalOcean, Vultr, etc.), by name or numeric ID.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: ValidateHostID,
+			},
+			"filter": filterSchema(imageItemSchema()),
+			"sort":   sortSchema(imageItemSchema()),
+			"images": {
+				Description: "The matching image versions.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: imageItemSchema()},
+			},
+		},
+	}
+}
+
+func flattenImageVersion(image *gobitlaunch.HostImage, version *gobitlaunch.HostImageVersion) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                   version.ID,
+		"distro_name":          image.Name,
+		"version_name":         version.Description,
+		"is_default":           version.ID == image.DefaultVersion.ID,
+		"type":                 image.Type,
+		"min_disk_size":        image.MinDiskSize,
+		"unavailable_regions":  image.UnavailableRegions,
+		"extra_cost_per_month": image.ExtraCostPerMonth,
+		"is_windows":           image.Windows,
+		"password_unsupported": version.PasswordUnsupported,
+	}
+}
+
+// imageVersions gets every version of an image. Some images only have the default
+// version, and for others it's also in the list of versions.
+func imageVersions(image *gobitlaunch.HostImage) []gobitlaunch.HostImageVersion {
+	versions := []gobitlaunch.HostImageVersion{image.DefaultVersion}
+	for _, version := range image.Versions {
+		if version.ID != image.DefaultVersion.ID {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+func dataSourceImagesRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Getting Images")
+
+	hostID, err := resolveHostID(data.Get("host").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ops, err := client.CreateOptions.Show(hostID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	images := []map[string]interface{}{}
+	for i := range ops.Images {
+		for _, version := range imageVersions(&ops.Images[i]) {
+			images = append(images, flattenImageVersion(&ops.Images[i], &version))
+		}
+	}
+	images, err = filterAndSortItems(data, images)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(strconv.Itoa(hostID))
+	if err := data.Set("images", images); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
@@ -0,0 +1,78 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccdataSourceImages(t *testing.T) {
+	api := newMockAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: api.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccdataSourceImages,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.bitlaunch_images.images", "images.#", "2"),
+					resource.TestCheckResourceAttr("data.bitlaunch_images.images", "images.0.id", "10001"),
+					resource.TestCheckResourceAttr("data.bitlaunch_images.images", "images.1.is_default", "true"),
+				),
+			},
+		},
+	})
+}
+
+const testAccdataSourceImages = `
+data "bitlaunch_images" "images" {
+  host = "BitLaunch"
+
+  filter {
+    name   = "distro_name"
+    values = ["Ubuntu"]
+  }
+
+  sort {
+    key       = "version_name"
+    direction = "desc"
+  }
+}
+`
+
+func TestDataSourceImagesRead(t *testing.T) {
+	api := newMockAPI(t)
+
+	data := schema.TestResourceDataRaw(t, dataSourceImages().Schema, map[string]interface{}{
+		"host": "BitLaunch",
+	})
+	if diags := dataSourceImagesRead(context.Background(), data, api.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	// Every default version plus the extra Ubuntu and Shadowsocks versions
+	if count := data.Get("images.#").(int); count != 11 {
+		t.Errorf("expected 11 image versions, got %d", count)
+	}
+
+	data = schema.TestResourceDataRaw(t, dataSourceImages().Schema, map[string]interface{}{
+		"host": "DigitalOcean",
+		"filter": []interface{}{
+			map[string]interface{}{"name": "unavailable_regions", "values": []interface{}{"sfo1"}},
+			map[string]interface{}{"name": "password_unsupported", "values": []interface{}{"true"}},
+		},
+	})
+	if diags := dataSourceImagesRead(context.Background(), data, api.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	for _, image := range data.Get("images").([]interface{}) {
+		if !image.(map[string]interface{})["password_unsupported"].(bool) {
+			t.Errorf("expected only images without password support, got %v", image)
+		}
+	}
+	if count := data.Get("images.#").(int); count == 0 {
+		t.Errorf("expected some images without password support")
+	}
+}
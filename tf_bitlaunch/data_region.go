@@ -19,7 +19,7 @@ func dataSourceRegion() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"host": {
-				Description:  "Host Provider (DigitalOcean, Vultr, etc.)",
+				Description:  "Host Provider (DigitalOcean, Vultr, etc.), by name or numeric ID.",
 				Type:         schema.TypeString,
 				Required:     true,
 				ValidateFunc: ValidateHostID,
@@ -81,7 +81,10 @@ func dataSourceRegionRead(ctx context.Context, data *schema.ResourceData, meta i
 	tflog.Trace(ctx, "Getting a Region")
 
 	hostName := data.Get("host").(string)
-	hostID := HostIDs[hostName]
+	hostID, err := resolveHostID(hostName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	ops, err := client.CreateOptions.Show(hostID)
 	if err != nil {
 		return diag.FromErr(err)
@@ -1,24 +1,24 @@
 package tf_bitlaunch
 
 import (
-	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 func TestAccdataSourceRegion(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
+	api := newMockAPI(t)
 
-	resource.UnitTest(t, resource.TestCase{
+	resource.Test(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
+		ProviderFactories: api.providerFactories(),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccdataSourceRegion,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.scaffolding_data_source.foo", "sample_attribute", regexp.MustCompile("^ba")),
+					resource.TestCheckResourceAttr("data.bitlaunch_region.region", "id", "ams1"),
+					resource.TestCheckResourceAttr("data.bitlaunch_region.region", "slug", "ams1"),
+					resource.TestCheckResourceAttr("data.bitlaunch_region.region", "unavailable_sizes.#", "4"),
 				),
 			},
 		},
@@ -26,7 +26,8 @@ func TestAccdataSourceRegion(t *testing.T) {
 }
 
 const testAccdataSourceRegion = `
-data "scaffolding_data_source" "foo" {
-  sample_attribute = "bar"
+data "bitlaunch_region" "region" {
+  host        = "BitLaunch"
+  region_name = "Amsterdam"
 }
 `
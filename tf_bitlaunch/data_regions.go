@@ -0,0 +1,132 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// regionItemSchema is the schema of each subregion in bitlaunch_regions
+func regionItemSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"id": {
+			Description: "The ID of the subregion, to use as a server's `region_id`.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"region_name": {
+			Description: "The name of the Region.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"slug": {
+			Description: "The Specific Subregion slug.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"is_default": {
+			Description: "If this is the subregion used by default for the region.",
+			Type:        schema.TypeBool,
+			Computed:    true,
+		},
+		"iso": {
+			Description: "The ISO code for the region.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"unavailable_sizes": {
+			Description: "A list of the unavailable sizes for this subregion.",
+			Type:        schema.TypeList,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Computed:    true,
+		},
+	}
+}
+
+func dataSourceRegions() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Lists every region and subregion available on a host, optionally filtered and sorted. Matches https://developers.bitlaunch.io/reference/host-region-object",
+
+		ReadContext: dataSourceRegionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Description:  "Host Provider (DigitalOcean, Vultr, etc.), by name or numeric ID.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: ValidateHostID,
+			},
+			"filter": filterSchema(regionItemSchema()),
+			"sort":   sortSchema(regionItemSchema()),
+			"regions": {
+				Description: "The matching subregions.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: regionItemSchema()},
+			},
+		},
+	}
+}
+
+func flattenSubregion(region *gobitlaunch.HostRegion, subregion *gobitlaunch.HostSubRegion) map[string]interface{} {
+	return map[string]interface{}{
+		"id": subregion.ID,
+		// The API doesn't trim some of these...
+		"region_name":       strings.TrimSpace(region.Name),
+		"slug":              subregion.Slug,
+		"is_default":        subregion.ID == region.DefaultSubregion.ID,
+		"iso":               region.ISO,
+		"unavailable_sizes": subregion.UnavailableSizes,
+	}
+}
+
+// subregions gets every subregion of a region. The default subregion may or may not
+// also be in the list of subregions.
+func subregions(region *gobitlaunch.HostRegion) []gobitlaunch.HostSubRegion {
+	subregions := []gobitlaunch.HostSubRegion{region.DefaultSubregion}
+	for _, subregion := range region.Subregions {
+		if subregion.ID != region.DefaultSubregion.ID {
+			subregions = append(subregions, subregion)
+		}
+	}
+	return subregions
+}
+
+func dataSourceRegionsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Getting Regions")
+
+	hostID, err := resolveHostID(data.Get("host").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ops, err := client.CreateOptions.Show(hostID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	regions := []map[string]interface{}{}
+	for i := range ops.Regions {
+		for _, subregion := range subregions(&ops.Regions[i]) {
+			regions = append(regions, flattenSubregion(&ops.Regions[i], &subregion))
+		}
+	}
+	regions, err = filterAndSortItems(data, regions)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(strconv.Itoa(hostID))
+	if err := data.Set("regions", regions); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
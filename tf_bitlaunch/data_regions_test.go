@@ -0,0 +1,62 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccdataSourceRegions(t *testing.T) {
+	api := newMockAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: api.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccdataSourceRegions,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.bitlaunch_regions.regions", "regions.#", "2"),
+					resource.TestCheckResourceAttr("data.bitlaunch_regions.regions", "regions.0.id", "lax1"),
+					resource.TestCheckResourceAttr("data.bitlaunch_regions.regions", "regions.1.id", "lon1"),
+				),
+			},
+		},
+	})
+}
+
+const testAccdataSourceRegions = `
+data "bitlaunch_regions" "regions" {
+  host = "BitLaunch"
+
+  filter {
+    name     = "region_name"
+    values   = ["^L"]
+    match_by = "regex"
+  }
+
+  sort {
+    key = "id"
+  }
+}
+`
+
+func TestDataSourceRegionsRead(t *testing.T) {
+	api := newMockAPI(t)
+
+	data := schema.TestResourceDataRaw(t, dataSourceRegions().Schema, map[string]interface{}{
+		"host": "DigitalOcean",
+	})
+	if diags := dataSourceRegionsRead(context.Background(), data, api.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	// Default subregions that are also in the list of subregions are only included once
+	if count := data.Get("regions.#").(int); count != 13 {
+		t.Errorf("expected 13 subregions, got %d", count)
+	}
+	if name := data.Get("regions.0.region_name").(string); name != "New York" {
+		t.Errorf("expected region names to be trimmed, got %q", name)
+	}
+}
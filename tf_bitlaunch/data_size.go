@@ -2,13 +2,25 @@ package tf_bitlaunch
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/slices"
 
 	"github.com/bitlaunchio/gobitlaunch"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// sizeConstraintKeys are the arguments that choose a size, at least one of which is needed
+var sizeConstraintKeys = []string{
+	"cpu_count", "disk_gb", "memory_mb",
+	"min_cpu_count", "min_disk_gb", "min_memory_mb", "min_bandwidth_gb", "max_cost_per_month",
+	"plan_type", "region_id", "prefer",
+}
+
 func dataSourceSize() *schema.Resource {
 	return &schema.Resource{
 		// This description is used by the documentation generator and the language server.
@@ -18,7 +30,7 @@ func dataSourceSize() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"host": {
-				Description:  "Host Provider (DigitalOcean, Vultr, etc.)",
+				Description:  "Host Provider (DigitalOcean, Vultr, etc.), by name or numeric ID.",
 				Type:         schema.TypeString,
 				Required:     true,
 				ValidateFunc: ValidateHostID,
@@ -27,19 +39,67 @@ func dataSourceSize() *schema.Resource {
 				Description:  "The amount of vCPU's included.",
 				Type:         schema.TypeInt,
 				Optional:     true,
-				AtLeastOneOf: []string{"cpu_count", "disk_gb", "memory_mb"},
+				AtLeastOneOf: sizeConstraintKeys,
 			},
 			"disk_gb": {
 				Description:  "The amount of disk space included.",
 				Type:         schema.TypeInt,
 				Optional:     true,
-				AtLeastOneOf: []string{"cpu_count", "disk_gb", "memory_mb"},
+				AtLeastOneOf: sizeConstraintKeys,
 			},
 			"memory_mb": {
 				Description:  "The amount of memory (RAM) included.",
 				Type:         schema.TypeInt,
 				Optional:     true,
-				AtLeastOneOf: []string{"cpu_count", "disk_gb", "memory_mb"},
+				AtLeastOneOf: sizeConstraintKeys,
+			},
+			"min_cpu_count": {
+				Description:  "The least vCPU's the size can have.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				AtLeastOneOf: sizeConstraintKeys,
+			},
+			"min_disk_gb": {
+				Description:  "The least disk space the size can have.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				AtLeastOneOf: sizeConstraintKeys,
+			},
+			"min_memory_mb": {
+				Description:  "The least memory (RAM) the size can have.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				AtLeastOneOf: sizeConstraintKeys,
+			},
+			"min_bandwidth_gb": {
+				Description:  "The least monthly bandwidth in GB the size can have.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				AtLeastOneOf: sizeConstraintKeys,
+			},
+			"max_cost_per_month": {
+				Description:  "The most the size can cost in USD per month.",
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				ValidateFunc: validation.FloatAtLeast(0),
+				AtLeastOneOf: sizeConstraintKeys,
+			},
+			"region_id": {
+				Description:  "Leave out sizes that aren't available in this region ID.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				AtLeastOneOf: sizeConstraintKeys,
+			},
+			"prefer": {
+				Description:  "Which size to pick when more than one matches: `cheapest` or `largest` (most memory, then vCPU's, then disk space). By default the first match is used, in the order the API gives them.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"cheapest", "largest"}, false),
+				AtLeastOneOf: sizeConstraintKeys,
 			},
 			"slug": {
 				Description: "A human readable string.",
@@ -62,9 +122,11 @@ func dataSourceSize() *schema.Resource {
 				Computed:    true,
 			},
 			"plan_type": {
-				Description: "Some hosts offer a different plan type for different usage. You should refer to the host documentation for more information.",
-				Type:        schema.TypeString,
-				Computed:    true,
+				Description:  "Some hosts offer a different plan type for different usage. You should refer to the host documentation for more information. If set, only sizes with this plan type match.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: sizeConstraintKeys,
 			},
 			"disks": {
 				Description: "Details on disks included with the size.",
@@ -130,16 +192,7 @@ func setDataSize(data *schema.ResourceData, size *gobitlaunch.HostSize, hostName
 		return diag.FromErr(err)
 	}
 
-	disks := make([]interface{}, len(size.Disks), len(size.Disks))
-	for i, disk := range size.Disks {
-		tfDisk := make(map[string]interface{})
-		tfDisk["type"] = disk.Type
-		tfDisk["count"] = disk.Count
-		tfDisk["size"] = disk.Size
-		tfDisk["unit"] = disk.Unit
-		disks[i] = tfDisk
-	}
-	if err := data.Set("disks", disks); err != nil {
+	if err := data.Set("disks", flattenDisks(size.Disks)); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -147,38 +200,122 @@ func setDataSize(data *schema.ResourceData, size *gobitlaunch.HostSize, hostName
 }
 
 func dataSourceSizeRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	var diags diag.Diagnostics
 	client := meta.(*apiClient).client
 	tflog.Trace(ctx, "Getting a Size")
 
 	hostName := data.Get("host").(string)
-	hostID := HostIDs[hostName]
+	hostID, err := resolveHostID(hostName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	ops, err := client.CreateOptions.Show(hostID)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	cpuCount := data.Get("cpu_count").(int)
-	diskGB := data.Get("disk_gb").(int)
-	memoryMB := data.Get("memory_mb").(int)
-	if cpuCount == 0 && diskGB == 0 && memoryMB == 0 {
-		return diag.Errorf("Require one of cpu_count, disk_gb, memory_mb")
+	size, err := selectSize(ops, sizeConstraints{
+		CPUCount:        data.Get("cpu_count").(int),
+		DiskGB:          data.Get("disk_gb").(int),
+		MemoryMB:        data.Get("memory_mb").(int),
+		MinCPUCount:     data.Get("min_cpu_count").(int),
+		MinDiskGB:       data.Get("min_disk_gb").(int),
+		MinMemoryMB:     data.Get("min_memory_mb").(int),
+		MinBandwidthGB:  data.Get("min_bandwidth_gb").(int),
+		MaxCostPerMonth: data.Get("max_cost_per_month").(float64),
+		PlanType:        data.Get("plan_type").(string),
+		RegionID:        data.Get("region_id").(string),
+		Prefer:          data.Get("prefer").(string),
+	})
+	if err != nil {
+		return diag.FromErr(err)
 	}
-	for _, size := range ops.Sizes {
-		if cpuCount != 0 && size.CPUCount != cpuCount {
-			continue
+
+	data.SetId(size.ID)
+	return setDataSize(data, size, hostName)
+}
+
+// sizeConstraints are what a size has to match. Zero values aren't checked.
+type sizeConstraints struct {
+	CPUCount        int
+	DiskGB          int
+	MemoryMB        int
+	MinCPUCount     int
+	MinDiskGB       int
+	MinMemoryMB     int
+	MinBandwidthGB  int
+	MaxCostPerMonth float64
+	PlanType        string
+	RegionID        string
+	Prefer          string
+}
+
+func (c sizeConstraints) matches(size *gobitlaunch.HostSize) bool {
+	switch {
+	case c.CPUCount != 0 && size.CPUCount != c.CPUCount,
+		c.DiskGB != 0 && size.DiskGB != c.DiskGB,
+		c.MemoryMB != 0 && size.MemoryMB != c.MemoryMB,
+		size.CPUCount < c.MinCPUCount,
+		size.DiskGB < c.MinDiskGB,
+		size.MemoryMB < c.MinMemoryMB,
+		size.BandwidthGB < c.MinBandwidthGB,
+		c.MaxCostPerMonth != 0 && size.CostPerMonth > c.MaxCostPerMonth,
+		c.PlanType != "" && size.PlanType != c.PlanType:
+		return false
+	}
+	return true
+}
+
+// preferSize checks if a is a better choice than b
+func preferSize(prefer string, a, b *gobitlaunch.HostSize) bool {
+	switch prefer {
+	case "cheapest":
+		if a.CostPerHour != b.CostPerHour {
+			return a.CostPerHour < b.CostPerHour
 		}
-		if diskGB != 0 && size.DiskGB != diskGB {
-			continue
+		return a.CostPerMonth < b.CostPerMonth
+	case "largest":
+		if a.MemoryMB != b.MemoryMB {
+			return a.MemoryMB > b.MemoryMB
 		}
-		if memoryMB != 0 && size.MemoryMB != memoryMB {
-			continue
+		if a.CPUCount != b.CPUCount {
+			return a.CPUCount > b.CPUCount
 		}
-		// Found the first matching?
-		data.SetId(size.ID)
-		setDataSize(data, &size, hostName)
-		return diags
+		return a.DiskGB > b.DiskGB
 	}
+	// Otherwise keep the first
+	return false
+}
 
-	return diag.Errorf("Can't find matching Size")
+// selectSize picks the size that matches the constraints, and is preferred over any others that do
+func selectSize(ops *hostCreateOptions, constraints sizeConstraints) (*gobitlaunch.HostSize, error) {
+	var unavailable []string
+	if constraints.RegionID != "" {
+		found := false
+		for i := range ops.Regions {
+			for _, subregion := range subregions(&ops.Regions[i]) {
+				if subregion.ID == constraints.RegionID {
+					unavailable = subregion.UnavailableSizes
+					found = true
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("region_id %q isn't available on this host", constraints.RegionID)
+		}
+	}
+
+	var selected *gobitlaunch.HostSize
+	for i := range ops.Sizes {
+		size := &ops.Sizes[i]
+		if !constraints.matches(size) || slices.Contains(unavailable, size.ID) {
+			continue
+		}
+		if selected == nil || preferSize(constraints.Prefer, size, selected) {
+			selected = size
+		}
+	}
+	if selected == nil {
+		return nil, errors.New("Can't find matching Size")
+	}
+	return selected, nil
 }
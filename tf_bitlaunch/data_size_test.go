@@ -1,24 +1,25 @@
 package tf_bitlaunch
 
 import (
-	"regexp"
+	"context"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func TestAccdataSourceSize(t *testing.T) {
-	t.Skip("data source not yet implemented, remove this once you add your own code")
+	api := newMockAPI(t)
 
-	resource.UnitTest(t, resource.TestCase{
+	resource.Test(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
+		ProviderFactories: api.providerFactories(),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccdataSourceSize,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"data.scaffolding_data_source.foo", "sample_attribute", regexp.MustCompile("^ba")),
+					resource.TestCheckResourceAttr("data.bitlaunch_size.size", "id", "nibble-4096"),
+					resource.TestCheckResourceAttr("data.bitlaunch_size.size", "cost_per_hour", "54"),
 				),
 			},
 		},
@@ -26,7 +27,68 @@ func TestAccdataSourceSize(t *testing.T) {
 }
 
 const testAccdataSourceSize = `
-data "scaffolding_data_source" "foo" {
-  sample_attribute = "bar"
+data "bitlaunch_size" "size" {
+  host      = "BitLaunch"
+  cpu_count = 2
+  memory_mb = 4096
 }
 `
+
+func TestSelectSize(t *testing.T) {
+	api := newMockAPI(t)
+	ops, err := api.client().client.CreateOptions.Show(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		constraints sizeConstraints
+		want        string
+	}{
+		"first match":         {sizeConstraints{CPUCount: 2}, "nibble-2048"},
+		"cheapest":            {sizeConstraints{MinCPUCount: 2, Prefer: "cheapest"}, "nibble-2048"},
+		"largest":             {sizeConstraints{MinCPUCount: 2, Prefer: "largest"}, "nibble-16384"},
+		"largest within cost": {sizeConstraints{MinMemoryMB: 4096, MaxCostPerMonth: 80, Prefer: "largest"}, "nibble-8192"},
+		"cheapest in range":   {sizeConstraints{MinMemoryMB: 4096, MaxCostPerMonth: 80, Prefer: "cheapest"}, "nibble-4096"},
+		"plan type":           {sizeConstraints{PlanType: "standard", Prefer: "cheapest"}, "nibble-1024"},
+		"region":              {sizeConstraints{RegionID: "ams1", Prefer: "largest"}, "nibble-1024"},
+		"no match":            {sizeConstraints{PlanType: "cpu"}, ""},
+		"missing region":      {sizeConstraints{RegionID: "nyc1"}, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			size, err := selectSize(ops, test.constraints)
+			if test.want == "" {
+				if err == nil {
+					t.Errorf("expected an error, got %s", size.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if size.ID != test.want {
+				t.Errorf("got %s, want %s", size.ID, test.want)
+			}
+		})
+	}
+}
+
+func TestDataSourceSizeRead(t *testing.T) {
+	api := newMockAPI(t)
+
+	data := schema.TestResourceDataRaw(t, dataSourceSize().Schema, map[string]interface{}{
+		"host":          "BitLaunch",
+		"min_cpu_count": 2,
+		"region_id":     "lon1",
+		"prefer":        "cheapest",
+	})
+	if diags := dataSourceSizeRead(context.Background(), data, api.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if data.Id() != "nibble-2048" {
+		t.Errorf("expected nibble-2048, got %q", data.Id())
+	}
+	if cpus := data.Get("cpu_count").(int); cpus != 2 {
+		t.Errorf("expected cpu_count to be set, got %d", cpus)
+	}
+}
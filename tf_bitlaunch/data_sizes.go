@@ -0,0 +1,176 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// sizeItemSchema is the schema of each size in bitlaunch_sizes
+func sizeItemSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"id": {
+			Description: "The ID of the size, to use as a server's `size_id`.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"slug": {
+			Description: "A human readable string.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"cpu_count": {
+			Description: "The amount of vCPU's included.",
+			Type:        schema.TypeInt,
+			Computed:    true,
+		},
+		"disk_gb": {
+			Description: "The amount of disk space included.",
+			Type:        schema.TypeInt,
+			Computed:    true,
+		},
+		"memory_mb": {
+			Description: "The amount of memory (RAM) included.",
+			Type:        schema.TypeInt,
+			Computed:    true,
+		},
+		"bandwidth_gb": {
+			Description: "The available monthly bandwidth in GB.",
+			Type:        schema.TypeInt,
+			Computed:    true,
+		},
+		"cost_per_hour": {
+			Description: "The amount of balance deducted per hour.",
+			Type:        schema.TypeInt,
+			Computed:    true,
+		},
+		"cost_per_month": {
+			Description: "The amount in USD charged per month.",
+			Type:        schema.TypeFloat,
+			Computed:    true,
+		},
+		"plan_type": {
+			Description: "Some hosts offer a different plan type for different usage. You should refer to the host documentation for more information.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+		"disks": {
+			Description: "Details on disks included with the size.",
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"type": {
+						Description: "The type of storage disk (SSD/HDD).",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"count": {
+						Description: "The amount of disks.",
+						Type:        schema.TypeInt,
+						Computed:    true,
+					},
+					"size": {
+						Description: "The size of the disk(s).",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+					"unit": {
+						Description: "The unit of measurement for the disk size.",
+						Type:        schema.TypeString,
+						Computed:    true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceSizes() *schema.Resource {
+	return &schema.Resource{
+		// This description is used by the documentation generator and the language server.
+		Description: "Lists every size available on a host, optionally filtered and sorted. Matches https://developers.bitlaunch.io/reference/host-size-object",
+
+		ReadContext: dataSourceSizesRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Description:  "Host Provider (DigitalOcean, Vultr, etc.), by name or numeric ID.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: ValidateHostID,
+			},
+			"filter": filterSchema(sizeItemSchema()),
+			"sort":   sortSchema(sizeItemSchema()),
+			"sizes": {
+				Description: "The matching sizes.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: sizeItemSchema()},
+			},
+		},
+	}
+}
+
+func flattenDisks(disks []gobitlaunch.HostDisks) []interface{} {
+	tfDisks := make([]interface{}, len(disks))
+	for i, disk := range disks {
+		tfDisk := make(map[string]interface{})
+		tfDisk["type"] = disk.Type
+		tfDisk["count"] = disk.Count
+		tfDisk["size"] = disk.Size
+		tfDisk["unit"] = disk.Unit
+		tfDisks[i] = tfDisk
+	}
+	return tfDisks
+}
+
+func flattenSize(size *gobitlaunch.HostSize) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             size.ID,
+		"slug":           size.Slug,
+		"cpu_count":      size.CPUCount,
+		"disk_gb":        size.DiskGB,
+		"memory_mb":      size.MemoryMB,
+		"bandwidth_gb":   size.BandwidthGB,
+		"cost_per_hour":  size.CostPerHour,
+		"cost_per_month": size.CostPerMonth,
+		"plan_type":      size.PlanType,
+		"disks":          flattenDisks(size.Disks),
+	}
+}
+
+func dataSourceSizesRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Getting Sizes")
+
+	hostID, err := resolveHostID(data.Get("host").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ops, err := client.CreateOptions.Show(hostID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	sizes := []map[string]interface{}{}
+	for i := range ops.Sizes {
+		sizes = append(sizes, flattenSize(&ops.Sizes[i]))
+	}
+	sizes, err = filterAndSortItems(data, sizes)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	data.SetId(strconv.Itoa(hostID))
+	if err := data.Set("sizes", sizes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
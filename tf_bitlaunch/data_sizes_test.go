@@ -0,0 +1,72 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccdataSourceSizes(t *testing.T) {
+	api := newMockAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: api.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccdataSourceSizes,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.bitlaunch_sizes.sizes", "sizes.#", "3"),
+					resource.TestCheckResourceAttr("data.bitlaunch_sizes.sizes", "sizes.0.id", "nibble-8192"),
+					resource.TestCheckResourceAttr("data.bitlaunch_sizes.sizes", "sizes.2.id", "nibble-2048"),
+				),
+			},
+		},
+	})
+}
+
+const testAccdataSourceSizes = `
+data "bitlaunch_sizes" "sizes" {
+  host = "BitLaunch"
+
+  filter {
+    name     = "cpu_count"
+    values   = ["2"]
+    match_by = "greater_than_or_equal"
+  }
+
+  filter {
+    name     = "cost_per_month"
+    values   = ["160"]
+    match_by = "less_than"
+  }
+
+  sort {
+    key       = "memory_mb"
+    direction = "desc"
+  }
+}
+`
+
+func TestDataSourceSizesRead(t *testing.T) {
+	api := newMockAPI(t)
+
+	data := schema.TestResourceDataRaw(t, dataSourceSizes().Schema, map[string]interface{}{
+		"host": "BitLaunch",
+		"sort": []interface{}{map[string]interface{}{"key": "cost_per_hour"}},
+	})
+	if diags := dataSourceSizesRead(context.Background(), data, api.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if count := data.Get("sizes.#").(int); count != 5 {
+		t.Fatalf("expected all 5 sizes, got %d", count)
+	}
+	if id := data.Get("sizes.0.id").(string); id != "nibble-1024" {
+		t.Errorf("expected the cheapest size first, got %q", id)
+	}
+	if disks := data.Get("sizes.0.disks.#").(int); disks == 0 {
+		t.Errorf("expected disks to be set")
+	}
+}
@@ -0,0 +1,259 @@
+package tf_bitlaunch
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// How filter values are compared. The numeric ones use the first value.
+var filterMatchBy = []string{"exact", "regex", "less_than", "less_than_or_equal", "greater_than", "greater_than_or_equal"}
+
+// listKeys gets the attributes of a plural data source's items that can be filtered
+// and sorted on. Nested blocks can't be.
+func listKeys(elem map[string]*schema.Schema, lists bool) []string {
+	keys := []string{}
+	for key, s := range elem {
+		switch s.Type {
+		case schema.TypeString, schema.TypeInt, schema.TypeFloat, schema.TypeBool:
+			keys = append(keys, key)
+		case schema.TypeList:
+			if _, ok := s.Elem.(*schema.Schema); ok && lists {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// filterSchema is the filter block for plural data sources
+func filterSchema(elem map[string]*schema.Schema) *schema.Schema {
+	return &schema.Schema{
+		Description: "Only include items that match. Items have to match every filter.",
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Description:  "The attribute to filter on. Lists match if any of their values do.",
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringInSlice(listKeys(elem, true), false),
+				},
+				"values": {
+					Description: "The values to match, any of which can match. Numeric comparisons only use the first value.",
+					Type:        schema.TypeList,
+					Required:    true,
+					MinItems:    1,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				"match_by": {
+					Description:  fmt.Sprintf("How to match the values, one of `%s`. Defaults to `exact`.", strings.Join(filterMatchBy, "`, `")),
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "exact",
+					ValidateFunc: validation.StringInSlice(filterMatchBy, false),
+				},
+			},
+		},
+	}
+}
+
+// sortSchema is the sort block for plural data sources
+func sortSchema(elem map[string]*schema.Schema) *schema.Schema {
+	return &schema.Schema{
+		Description: "How to order the items. Later sorts are used when earlier ones are equal.",
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Description:  "The attribute to sort by.",
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringInSlice(listKeys(elem, false), false),
+				},
+				"direction": {
+					Description:  "Either `asc` or `desc`. Defaults to `asc`.",
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "asc",
+					ValidateFunc: validation.StringInSlice([]string{"asc", "desc"}, false),
+				},
+			},
+		},
+	}
+}
+
+type listFilter struct {
+	Name    string
+	Values  []string
+	MatchBy string
+
+	regexps []*regexp.Regexp
+	number  float64
+}
+
+// expandFilters reads the filter blocks, checking the values can be used with match_by
+func expandFilters(raw []interface{}) ([]listFilter, error) {
+	filters := []listFilter{}
+	for _, r := range raw {
+		m := r.(map[string]interface{})
+		f := listFilter{
+			Name:    m["name"].(string),
+			MatchBy: m["match_by"].(string),
+		}
+		for _, v := range m["values"].([]interface{}) {
+			value, _ := v.(string)
+			f.Values = append(f.Values, value)
+		}
+
+		switch f.MatchBy {
+		case "exact":
+		case "regex":
+			for _, value := range f.Values {
+				re, err := regexp.Compile(value)
+				if err != nil {
+					return nil, fmt.Errorf("filter on %s: invalid regex %q: %w", f.Name, value, err)
+				}
+				f.regexps = append(f.regexps, re)
+			}
+		default:
+			number, err := strconv.ParseFloat(f.Values[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("filter on %s: %s needs a number, got %q", f.Name, f.MatchBy, f.Values[0])
+			}
+			f.number = number
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// itemStrings gets an attribute of an item as strings, with lists giving a string for each value
+func itemStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := []string{}
+		for _, e := range v {
+			values = append(values, itemStrings(e)...)
+		}
+		return values
+	case nil:
+		return nil
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func (f listFilter) matchesValue(value string) bool {
+	switch f.MatchBy {
+	case "exact":
+		return slices.Contains(f.Values, value)
+	case "regex":
+		return slices.ContainsFunc(f.regexps, func(re *regexp.Regexp) bool { return re.MatchString(value) })
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	switch f.MatchBy {
+	case "less_than":
+		return number < f.number
+	case "less_than_or_equal":
+		return number <= f.number
+	case "greater_than":
+		return number > f.number
+	case "greater_than_or_equal":
+		return number >= f.number
+	}
+	return false
+}
+
+func (f listFilter) matches(item map[string]interface{}) bool {
+	return slices.ContainsFunc(itemStrings(item[f.Name]), f.matchesValue)
+}
+
+type listSort struct {
+	Key        string
+	Descending bool
+}
+
+func expandSorts(raw []interface{}) []listSort {
+	sorts := []listSort{}
+	for _, r := range raw {
+		m := r.(map[string]interface{})
+		sorts = append(sorts, listSort{
+			Key:        m["key"].(string),
+			Descending: m["direction"].(string) == "desc",
+		})
+	}
+	return sorts
+}
+
+// compareItemValues orders two values of the same attribute
+func compareItemValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		return a - b.(int)
+	case float64:
+		switch {
+		case a < b.(float64):
+			return -1
+		case a > b.(float64):
+			return 1
+		}
+		return 0
+	case bool:
+		switch {
+		case a == b.(bool):
+			return 0
+		case a:
+			return 1
+		}
+		return -1
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// filterAndSortItems applies the filter and sort blocks of a plural data source to its items
+func filterAndSortItems(data *schema.ResourceData, items []map[string]interface{}) ([]map[string]interface{}, error) {
+	filters, err := expandFilters(data.Get("filter").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	sorts := expandSorts(data.Get("sort").([]interface{}))
+
+	matched := []map[string]interface{}{}
+	for _, item := range items {
+		if !slices.ContainsFunc(filters, func(f listFilter) bool { return !f.matches(item) }) {
+			matched = append(matched, item)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		for _, s := range sorts {
+			c := compareItemValues(matched[i][s.Key], matched[j][s.Key])
+			if s.Descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	return matched, nil
+}
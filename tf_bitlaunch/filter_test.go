@@ -0,0 +1,111 @@
+package tf_bitlaunch
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testFilterItems filters and sorts items with the filter and sort blocks from config, returning the IDs
+func testFilterItems(t *testing.T, items []map[string]interface{}, config map[string]interface{}) ([]string, error) {
+	t.Helper()
+	data := schema.TestResourceDataRaw(t, dataSourceSizes().Schema, config)
+	matched, err := filterAndSortItems(data, items)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, item := range matched {
+		ids = append(ids, item["id"].(string))
+	}
+	return ids, nil
+}
+
+func TestFilterAndSortItems(t *testing.T) {
+	items := []map[string]interface{}{
+		{"id": "a", "cpu_count": 1, "cost_per_month": 10.0, "plan_type": "standard", "disks": []interface{}{}},
+		{"id": "b", "cpu_count": 2, "cost_per_month": 20.0, "plan_type": "cpu", "disks": []interface{}{}},
+		{"id": "c", "cpu_count": 2, "cost_per_month": 40.0, "plan_type": "standard", "disks": []interface{}{}},
+		{"id": "d", "cpu_count": 4, "cost_per_month": 80.5, "plan_type": "standard", "disks": []interface{}{}},
+	}
+
+	for name, test := range map[string]struct {
+		config map[string]interface{}
+		want   []string
+	}{
+		"no filters": {
+			config: map[string]interface{}{},
+			want:   []string{"a", "b", "c", "d"},
+		},
+		"exact": {
+			config: map[string]interface{}{"filter": []interface{}{
+				map[string]interface{}{"name": "cpu_count", "values": []interface{}{"2", "4"}},
+			}},
+			want: []string{"b", "c", "d"},
+		},
+		"regex": {
+			config: map[string]interface{}{"filter": []interface{}{
+				map[string]interface{}{"name": "plan_type", "values": []interface{}{"^std", "^cpu$"}, "match_by": "regex"},
+			}},
+			want: []string{"b"},
+		},
+		"numeric and combined": {
+			config: map[string]interface{}{"filter": []interface{}{
+				map[string]interface{}{"name": "cpu_count", "values": []interface{}{"2"}, "match_by": "greater_than_or_equal"},
+				map[string]interface{}{"name": "cost_per_month", "values": []interface{}{"80.5"}, "match_by": "less_than"},
+			}},
+			want: []string{"b", "c"},
+		},
+		"sort": {
+			config: map[string]interface{}{"sort": []interface{}{
+				map[string]interface{}{"key": "cpu_count", "direction": "desc"},
+				map[string]interface{}{"key": "cost_per_month"},
+			}},
+			want: []string{"d", "b", "c", "a"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := testFilterItems(t, items, test.config)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+			for i := range test.want {
+				if got[i] != test.want[i] {
+					t.Fatalf("got %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+
+	for name, filter := range map[string]map[string]interface{}{
+		"invalid regex": {"name": "plan_type", "values": []interface{}{"("}, "match_by": "regex"},
+		"not a number":  {"name": "cpu_count", "values": []interface{}{"two"}, "match_by": "less_than"},
+	} {
+		if _, err := testFilterItems(t, items, map[string]interface{}{"filter": []interface{}{filter}}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestListKeys(t *testing.T) {
+	keys := listKeys(regionItemSchema(), true)
+	want := []string{"id", "is_default", "iso", "region_name", "slug", "unavailable_sizes"}
+	if len(keys) != len(want) {
+		t.Fatalf("got %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("got %v, want %v", keys, want)
+		}
+	}
+
+	// Nested blocks and lists can't be sorted on
+	for _, key := range listKeys(sizeItemSchema(), false) {
+		if key == "disks" {
+			t.Errorf("expected disks not to be sortable")
+		}
+	}
+}
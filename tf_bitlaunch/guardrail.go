@@ -0,0 +1,101 @@
+package tf_bitlaunch
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// plannedServer is a new server in the plan, for spendGuard. CostKnown is only set
+// if CostPerHour was found for the size.
+type plannedServer struct {
+	Name        string
+	SizeID      string
+	CostPerHour int
+	CostKnown   bool
+}
+
+// spendGuard adds up the servers planned by a provider instance, so a plan that would
+// create too many, or cost too much per hour, fails before anything is created.
+// Servers being replaced aren't counted, as the old server is deleted.
+type spendGuard struct {
+	MaxHourlySpend int
+	MaxServers     int
+
+	mu             sync.Mutex
+	loaded         bool
+	runningCost    int
+	runningServers int
+	planned        []plannedServer
+}
+
+// enabled checks if there are any limits. A nil spendGuard has none.
+func (g *spendGuard) enabled() bool {
+	return g != nil && (g.MaxHourlySpend > 0 || g.MaxServers > 0)
+}
+
+// plan adds a new server, failing if it takes the totals over the limits
+func (g *spendGuard) plan(client *bitlaunchClient, server plannedServer) error {
+	if !g.enabled() {
+		return nil
+	}
+
+	// Counting an unknown cost as 0 would let any spend through
+	if g.MaxHourlySpend > 0 && !server.CostKnown {
+		if server.SizeID == "" {
+			return fmt.Errorf("can't check server %q against max_hourly_spend, as its size_id isn't known until apply", server.Name)
+		}
+		return fmt.Errorf("can't check server %q against max_hourly_spend, as the hourly cost of size %q isn't known", server.Name, server.SizeID)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// What's already running only needs getting once per run
+	if !g.loaded {
+		account, err := client.Account.Show()
+		if err != nil {
+			return fmt.Errorf("failed to get the account to check max_hourly_spend and max_servers: %w", err)
+		}
+		g.runningCost = account.CostPerHr
+		g.runningServers = account.Used
+		g.loaded = true
+	}
+
+	planned := append(g.planned, server)
+	cost := g.runningCost
+	for _, p := range planned {
+		cost += p.CostPerHour
+	}
+	servers := g.runningServers + len(planned)
+
+	var exceeded []string
+	if g.MaxHourlySpend > 0 && cost > g.MaxHourlySpend {
+		exceeded = append(exceeded, fmt.Sprintf("an hourly spend of %d is over max_hourly_spend of %d", cost, g.MaxHourlySpend))
+	}
+	if g.MaxServers > 0 && servers > g.MaxServers {
+		exceeded = append(exceeded, fmt.Sprintf("%d servers is over max_servers of %d", servers, g.MaxServers))
+	}
+	if len(exceeded) > 0 {
+		return fmt.Errorf("creating server %q would go over the provider limits: %s\n\n%s",
+			server.Name, strings.Join(exceeded, " and "), g.breakdown(planned))
+	}
+
+	g.planned = planned
+	return nil
+}
+
+// breakdown lists what makes up the totals
+func (g *spendGuard) breakdown(planned []plannedServer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Already running: %d servers costing %d per hour\n", g.runningServers, g.runningCost)
+	fmt.Fprintf(&b, "Planned:\n")
+	for _, p := range planned {
+		size := p.SizeID
+		if size == "" {
+			size = "size not known until apply"
+		}
+		fmt.Fprintf(&b, "  %s (%s): %d per hour\n", p.Name, size, p.CostPerHour)
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testUnknownValue is how a raw config has a value that isn't known until apply
+const testUnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func TestSpendGuard(t *testing.T) {
+	api := newMockAPI(t)
+	client := api.client().client
+	running := testServer()
+	running.Rate = 14
+	api.addServer(running)
+
+	// No limits, so the account isn't needed
+	var guard *spendGuard
+	if err := guard.plan(client, plannedServer{Name: "web", CostPerHour: 1000}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if calls := api.callCount("GET /user"); calls != 0 {
+		t.Errorf("expected no account calls without limits, got %d", calls)
+	}
+
+	guard = &spendGuard{MaxHourlySpend: 50, MaxServers: 3}
+	if err := guard.plan(client, plannedServer{Name: "web", SizeID: "nibble-1024", CostPerHour: 14, CostKnown: true}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err := guard.plan(client, plannedServer{Name: "db", SizeID: "nibble-2048", CostPerHour: 27, CostKnown: true})
+	if err == nil {
+		t.Fatalf("expected going over max_hourly_spend to fail")
+	}
+	for _, want := range []string{`"db"`, "55 is over max_hourly_spend of 50", "1 servers costing 14", "web (nibble-1024): 14", "db (nibble-2048): 27"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %s", want, err)
+		}
+	}
+
+	// The failed server isn't counted
+	if err := guard.plan(client, plannedServer{Name: "cache", SizeID: "nibble-1024", CostPerHour: 14, CostKnown: true}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Fails closed, rather than counting the cost as 0
+	for _, server := range []plannedServer{{Name: "later"}, {Name: "custom", SizeID: "custom-1"}} {
+		err = guard.plan(client, server)
+		if err == nil || !strings.Contains(err.Error(), "can't check server") {
+			t.Errorf("expected an unknown cost to fail, got %v", err)
+		}
+	}
+
+	// Only max_hourly_spend needs the cost
+	guard.MaxHourlySpend = 0
+	err = guard.plan(client, plannedServer{Name: "later"})
+	if err == nil || !strings.Contains(err.Error(), "4 servers is over max_servers of 3") {
+		t.Errorf("expected going over max_servers to fail, got %v", err)
+	}
+
+	if calls := api.callCount("GET /user"); calls != 1 {
+		t.Errorf("expected the account to be got once, got %d calls", calls)
+	}
+}
+
+func TestResourceServerCustomizeDiffSpendGuard(t *testing.T) {
+	newMockAPI(t)
+	meta := testConfigureProvider(t, map[string]interface{}{"max_servers": 1})
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":      "BitLaunch",
+		"name":      "web",
+		"image_id":  "10000",
+		"size_id":   "nibble-1024",
+		"region_id": "lon1",
+	})
+
+	if _, err := resourceServer().SimpleDiff(context.Background(), nil, config, meta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err := resourceServer().SimpleDiff(context.Background(), nil, config, meta)
+	if err == nil || !strings.Contains(err.Error(), "max_servers") {
+		t.Errorf("expected a second server to go over max_servers, got %v", err)
+	}
+
+	// The size's cost can't be looked up until apply
+	meta = testConfigureProvider(t, map[string]interface{}{"max_hourly_spend": 1000})
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":      "BitLaunch",
+		"name":      "web",
+		"image_id":  "10000",
+		"size_id":   testUnknownValue,
+		"region_id": "lon1",
+	})
+	_, err = resourceServer().SimpleDiff(context.Background(), nil, config, meta)
+	if err == nil || !strings.Contains(err.Error(), "size_id isn't known until apply") {
+		t.Errorf("expected an unknown size to fail max_hourly_spend, got %v", err)
+	}
+}
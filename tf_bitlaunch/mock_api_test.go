@@ -0,0 +1,597 @@
+package tf_bitlaunch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/crypto/ssh"
+)
+
+// Create options fixtures, by host ID
+var mockCreateOptionsFixtures = map[int]string{
+	0: "../host_create_example_do.json",
+	4: "../host_create_example.json",
+}
+
+const mockToken = "mock-token"
+
+func init() {
+	// The mock changes state straight away, so there's nothing to wait for
+	serverUpdateDelay = 0
+	portWaitInterval = 10 * time.Millisecond
+}
+
+// mockAPI is an in-memory stand-in for the BitLaunch API, so tests can create,
+// read and delete things without a network or an account
+type mockAPI struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	nextID        int
+	nextIP        int
+	ipv4          string // given to new servers instead of a made up address, e.g. a local listener
+	servers       map[string]*gobitlaunch.Server
+	sshKeys       map[string]*gobitlaunch.SSHKey
+	transactions  map[string]*gobitlaunch.Transaction
+	createOptions map[int]json.RawMessage
+	account       gobitlaunch.Account
+	calls         map[string]int
+	failures      map[string][]mockFailure
+}
+
+// mockFailure makes a call fail. If handled is set, the call still takes effect
+// before failing, like a request that worked but timed out on the way back.
+type mockFailure struct {
+	StatusCode int
+	Handled    bool
+	RetryAfter string
+}
+
+// newMockAPI starts a mock API that is closed at the end of the test
+func newMockAPI(t *testing.T) *mockAPI {
+	t.Helper()
+	t.Setenv("BITLAUNCH_API_TOKEN", mockToken)
+
+	m := &mockAPI{
+		servers:       map[string]*gobitlaunch.Server{},
+		sshKeys:       map[string]*gobitlaunch.SSHKey{},
+		transactions:  map[string]*gobitlaunch.Transaction{},
+		createOptions: map[int]json.RawMessage{},
+		calls:         map[string]int{},
+		failures:      map[string][]mockFailure{},
+		account: gobitlaunch.Account{
+			ID:      "mock-account",
+			Email:   "mock@example.com",
+			Created: time.Now().UTC(),
+			Balance: 100000,
+			Limit:   10,
+		},
+	}
+	for hostID, path := range mockCreateOptionsFixtures {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to load create options fixture: %s", err)
+		}
+		m.createOptions[hostID] = b
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /hosts-create-options/{hostID}", m.showCreateOptions)
+	mux.HandleFunc("GET /servers", m.listServers)
+	mux.HandleFunc("POST /servers", m.createServer)
+	mux.HandleFunc("GET /servers/{id}", m.showServer)
+	mux.HandleFunc("DELETE /servers/{id}", m.destroyServer)
+	mux.HandleFunc("POST /servers/{id}/resize", m.resizeServer)
+	mux.HandleFunc("POST /servers/{id}/rebuild", m.rebuildServer)
+	mux.HandleFunc("POST /servers/{id}/restart", m.restartServer)
+	mux.HandleFunc("POST /servers/{id}/protection", m.setServerProtection)
+	mux.HandleFunc("POST /servers/{id}/protection/ports", m.setServerPorts)
+	mux.HandleFunc("GET /ssh-keys", m.listSSHKeys)
+	mux.HandleFunc("POST /ssh-keys", m.createSSHKey)
+	mux.HandleFunc("DELETE /ssh-keys/{id}", m.deleteSSHKey)
+	mux.HandleFunc("GET /transactions", m.listTransactions)
+	mux.HandleFunc("POST /transactions", m.createTransaction)
+	mux.HandleFunc("GET /transactions/{id}", m.showTransaction)
+	mux.HandleFunc("GET /user", m.showAccount)
+	mux.HandleFunc("GET /usage", m.showUsage)
+
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer: "+mockToken {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		endpoint := r.Method + " " + r.URL.Path
+		m.mu.Lock()
+		m.calls[endpoint]++
+		var failure *mockFailure
+		if len(m.failures[endpoint]) > 0 {
+			failure = &m.failures[endpoint][0]
+			m.failures[endpoint] = m.failures[endpoint][1:]
+		}
+		m.mu.Unlock()
+
+		if failure == nil {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		if failure.Handled {
+			mux.ServeHTTP(httptest.NewRecorder(), r)
+		}
+		if failure.RetryAfter != "" {
+			w.Header().Set("Retry-After", failure.RetryAfter)
+		}
+		http.Error(w, http.StatusText(failure.StatusCode), failure.StatusCode)
+	}))
+	t.Cleanup(m.Close)
+	t.Setenv("BITLAUNCH_API_URL", m.URL)
+
+	return m
+}
+
+// client gets an API client pointed at the mock
+func (m *mockAPI) client() *apiClient {
+	return &apiClient{client: newBitlaunchClient(mockToken, m.URL, m.Server.Client(), retryPolicy{}, nil)}
+}
+
+// providerFactories gets provider factories for acceptance tests. The provider
+// finds the mock through BITLAUNCH_API_URL.
+func (m *mockAPI) providerFactories() map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		"bitlaunch": func() (*schema.Provider, error) {
+			return New("dev")(), nil
+		},
+	}
+}
+
+// callCount gets how many times an endpoint was called, e.g. "GET /servers"
+func (m *mockAPI) callCount(endpoint string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[endpoint]
+}
+
+// failNext makes the next call to an endpoint fail, e.g. "POST /servers"
+func (m *mockAPI) failNext(endpoint string, failure mockFailure) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures[endpoint] = append(m.failures[endpoint], failure)
+}
+
+// addServer puts a server straight into the mock, returning its ID
+func (m *mockAPI) addServer(server gobitlaunch.Server) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server.ID = m.newID()
+	m.servers[server.ID] = &server
+	return server.ID
+}
+
+func (m *mockAPI) newID() string {
+	m.nextID++
+	return fmt.Sprintf("%024x", m.nextID)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (m *mockAPI) parseCreateOptions(hostID int) (*hostCreateOptions, bool) {
+	raw, ok := m.createOptions[hostID]
+	if !ok {
+		return nil, false
+	}
+	ops := hostCreateOptions{}
+	if err := json.Unmarshal(raw, &ops); err != nil {
+		return nil, false
+	}
+	return &ops, true
+}
+
+func (m *mockAPI) showCreateOptions(w http.ResponseWriter, r *http.Request) {
+	hostID, err := strconv.Atoi(r.PathValue("hostID"))
+	if err != nil {
+		http.Error(w, "invalid host", http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	raw, ok := m.createOptions[hostID]
+	m.mu.Unlock()
+	if !ok {
+		http.Error(w, "host not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(raw)
+}
+
+// getServer gets a server, moving it along to "ok" if it was in the middle of something.
+// Must be called with the lock held.
+func (m *mockAPI) getServer(id string) (*gobitlaunch.Server, bool) {
+	server, ok := m.servers[id]
+	if !ok {
+		return nil, false
+	}
+	switch server.Status {
+	case "ok", "stopped", "error", "destroyed":
+	default:
+		server.Status = "ok"
+	}
+	if server.Status == "ok" && server.Ipv4 == "" {
+		m.nextIP++
+		server.Ipv4 = fmt.Sprintf("10.0.%d.%d", m.nextIP/250, m.nextIP%250+1)
+		if m.ipv4 != "" {
+			server.Ipv4 = m.ipv4
+		}
+	}
+	return server, true
+}
+
+func (m *mockAPI) listServers(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	servers := []gobitlaunch.Server{}
+	for _, server := range m.servers {
+		servers = append(servers, *server)
+	}
+	writeJSON(w, servers)
+}
+
+// mockServerRate adds 20% to the rate of servers with backups, like DigitalOcean
+func mockServerRate(costPerHour int, backups bool) int {
+	if backups {
+		return costPerHour + costPerHour/5
+	}
+	return costPerHour
+}
+
+func (m *mockAPI) createServer(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		Server gobitlaunch.CreateServerOptions `json:"server"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts := body.Server
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ops, ok := m.parseCreateOptions(opts.HostID)
+	if !ok {
+		http.Error(w, "host not found", http.StatusBadRequest)
+		return
+	}
+	_, version := findImageVersion(&ops.ServerCreateOptions, opts.HostImageID)
+	if version == nil {
+		http.Error(w, "image not found", http.StatusBadRequest)
+		return
+	}
+	var size *gobitlaunch.HostSize
+	for i := range ops.Sizes {
+		if ops.Sizes[i].ID == opts.SizeID {
+			size = &ops.Sizes[i]
+		}
+	}
+	if size == nil {
+		http.Error(w, "size not found", http.StatusBadRequest)
+		return
+	}
+	for _, keyID := range opts.SSHKeys {
+		if _, ok := m.sshKeys[keyID]; !ok {
+			http.Error(w, "ssh key not found", http.StatusBadRequest)
+			return
+		}
+	}
+
+	server := &gobitlaunch.Server{
+		ID:        m.newID(),
+		Name:      opts.Name,
+		HostID:    opts.HostID,
+		Region:    opts.RegionID,
+		Size:      opts.SizeID,
+		Image:     opts.HostImageID,
+		ImageDesc: version.Description,
+		Created:   time.Now().UTC().Truncate(time.Second),
+		Rate:      size.CostPerHour,
+		DiskGB:    size.DiskGB,
+		Status:    "pending",
+	}
+	m.servers[server.ID] = server
+	writeJSON(w, server)
+}
+
+func (m *mockAPI) showServer(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server, ok := m.getServer(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"server": server})
+}
+
+func (m *mockAPI) destroyServer(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.servers[r.PathValue("id")]; !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	delete(m.servers, r.PathValue("id"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (m *mockAPI) resizeServer(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		Size string `json:"size"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server, ok := m.servers[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	ops, _ := m.parseCreateOptions(server.HostID)
+	for _, size := range ops.Sizes {
+		if size.ID == body.Size {
+			server.Size = size.ID
+			server.Rate = mockServerRate(size.CostPerHour, server.BackupsEnabled)
+			server.DiskGB = size.DiskGB
+			server.Status = "resizing"
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	http.Error(w, "size not found", http.StatusBadRequest)
+}
+
+func (m *mockAPI) rebuildServer(w http.ResponseWriter, r *http.Request) {
+	body := gobitlaunch.RebuildOptions{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server, ok := m.servers[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	ops, _ := m.parseCreateOptions(server.HostID)
+	_, version := findImageVersion(&ops.ServerCreateOptions, body.ID)
+	if version == nil {
+		http.Error(w, "image not found", http.StatusBadRequest)
+		return
+	}
+	server.Image = version.ID
+	server.ImageDesc = version.Description
+	server.Status = "rebuilding"
+	w.WriteHeader(http.StatusOK)
+}
+
+func (m *mockAPI) restartServer(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server, ok := m.servers[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	server.Status = "restarting"
+	w.WriteHeader(http.StatusOK)
+}
+
+func (m *mockAPI) setServerProtection(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		Enabled bool   `json:"enable"`
+		Region  string `json:"region"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server, ok := m.getServer(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	if body.Enabled && body.Region == "" {
+		http.Error(w, "region is required", http.StatusBadRequest)
+		return
+	}
+	// Turning protection on or off starts again with no ports
+	server.Protection = gobitlaunch.Server{}.Protection
+	server.Protection.Enabled = body.Enabled
+	if body.Enabled {
+		m.nextIP++
+		server.Protection.Proxy.IP = fmt.Sprintf("192.0.2.%d", m.nextIP%250+1)
+		server.Protection.Proxy.Region = body.Region
+		server.Protection.Proxy.Target = server.Ipv4
+		server.Protection.Proxy.Ports = []gobitlaunch.Ports{}
+	}
+	writeJSON(w, server)
+}
+
+func (m *mockAPI) setServerPorts(w http.ResponseWriter, r *http.Request) {
+	ports := []gobitlaunch.Ports{}
+	if err := json.NewDecoder(r.Body).Decode(&ports); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, port := range ports {
+		if port.PortNumber < 1 || port.PortNumber > 65535 || (port.Protocol != "tcp" && port.Protocol != "udp") {
+			http.Error(w, "invalid port", http.StatusBadRequest)
+			return
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server, ok := m.getServer(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+	if !server.Protection.Enabled {
+		http.Error(w, "protection isn't enabled", http.StatusBadRequest)
+		return
+	}
+	server.Protection.Proxy.Ports = ports
+	writeJSON(w, server)
+}
+
+func (m *mockAPI) listSSHKeys(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := []gobitlaunch.SSHKey{}
+	for _, key := range m.sshKeys {
+		keys = append(keys, *key)
+	}
+	writeJSON(w, map[string]interface{}{"keys": keys})
+}
+
+func (m *mockAPI) createSSHKey(w http.ResponseWriter, r *http.Request) {
+	key := gobitlaunch.SSHKey{}
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.Content))
+	if err != nil {
+		http.Error(w, "invalid ssh key", http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key.ID = m.newID()
+	key.Fingerprint = ssh.FingerprintLegacyMD5(pubKey)
+	key.Created = time.Now().UTC().Truncate(time.Second)
+	m.sshKeys[key.ID] = &key
+	writeJSON(w, key)
+}
+
+func (m *mockAPI) deleteSSHKey(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sshKeys[r.PathValue("id")]; !ok {
+		http.Error(w, "ssh key not found", http.StatusNotFound)
+		return
+	}
+	delete(m.sshKeys, r.PathValue("id"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (m *mockAPI) listTransactions(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	history := []gobitlaunch.Transaction{}
+	for _, transaction := range m.transactions {
+		history = append(history, *transaction)
+	}
+	writeJSON(w, map[string]interface{}{"history": history})
+}
+
+func (m *mockAPI) createTransaction(w http.ResponseWriter, r *http.Request) {
+	opts := gobitlaunch.CreateTransactionOptions{}
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	transaction := &gobitlaunch.Transaction{
+		ID:        m.newID(),
+		Date:      time.Now().UTC().Truncate(time.Second),
+		Symbol:    strings.ToUpper(opts.CryptoSymbol),
+		AmountUSD: float64(opts.AmountUSD),
+		Status:    "Pending",
+	}
+	m.transactions[transaction.ID] = transaction
+	writeJSON(w, transaction)
+}
+
+func (m *mockAPI) showTransaction(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	transaction, ok := m.transactions[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "transaction not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, transaction)
+}
+
+func (m *mockAPI) showAccount(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	account := m.account
+	account.Used = len(m.servers)
+	account.CostPerHr = 0
+	for _, server := range m.servers {
+		account.CostPerHr += server.Rate
+	}
+	writeJSON(w, account)
+}
+
+// showUsage charges for an hour of every server
+func (m *mockAPI) showUsage(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "latest" {
+		period = time.Now().UTC().Format("2006-01")
+	}
+	month, err := time.Parse("2006-01", period)
+	if err != nil {
+		http.Error(w, "invalid period", http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage := accountUsage{
+		Server:     []usageEntry{},
+		Backup:     []usageEntry{},
+		Bandwidth:  []usageEntry{},
+		Protection: []usageEntry{},
+		PrevMonth:  month.AddDate(0, -1, 0).Format("2006-01"),
+		ThisMonth:  month.Format("2006-01"),
+		NextMonth:  month.AddDate(0, 1, 0).Format("2006-01"),
+	}
+	for _, server := range m.servers {
+		usage.Server = append(usage.Server, usageEntry{
+			Description: server.Name,
+			Start:       server.Created,
+			End:         server.Created.Add(time.Hour),
+			Cost:        server.Rate,
+			Hours:       1,
+			Amount:      server.Rate,
+			Type:        "server",
+		})
+		usage.TotalUSD += server.Rate
+	}
+	writeJSON(w, usage)
+}
@@ -3,13 +3,16 @@ package tf_bitlaunch
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/bitlaunchio/gobitlaunch"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // https://developers.bitlaunch.io/reference/view-host-create-options
@@ -20,11 +23,55 @@ var HostIDs = map[string]int{
 	"BitLaunch":    4,
 }
 
+// knownHostNames gets the names in HostIDs, sorted by ID
+func knownHostNames() []string {
+	names := maps.Keys(HostIDs)
+	slices.SortFunc(names, func(a, b string) bool { return HostIDs[a] < HostIDs[b] })
+	return names
+}
+
+// resolveHostID gets the ID of a host from its name, in any case, or from a numeric ID.
+// Numeric IDs let hosts BitLaunch adds be used before they're in HostIDs.
+func resolveHostID(host string) (int, error) {
+	if id, err := strconv.Atoi(host); err == nil && id >= 0 {
+		return id, nil
+	}
+	for name, id := range HostIDs {
+		if strings.EqualFold(name, host) {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("must be a numeric host ID or one of %s", knownHostNames())
+}
+
 func ValidateHostID(val interface{}, key string) (warns []string, errs []error) {
-	hostName := val.(string)
-	hostNames := maps.Keys(HostIDs)
-	if !slices.Contains(hostNames, hostName) {
-		errs = append(errs, fmt.Errorf("%q Must be one of %s", key, hostNames))
+	if _, err := resolveHostID(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q %s", key, err))
+	}
+	return
+}
+
+// suppressEquivalentHost ignores changes between names and IDs of the same host
+func suppressEquivalentHost(k, old, new string, data *schema.ResourceData) bool {
+	oldID, oldErr := resolveHostID(old)
+	newID, newErr := resolveHostID(new)
+	return oldErr == nil && newErr == nil && oldID == newID
+}
+
+// hostNameFromID does the reverse lookup of HostIDs, for when the API only gives us the number.
+// Hosts we don't know the name of are left as the number.
+func hostNameFromID(hostID int) string {
+	for name, id := range HostIDs {
+		if id == hostID {
+			return name
+		}
+	}
+	return strconv.Itoa(hostID)
+}
+
+func validateDuration(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := time.ParseDuration(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a duration like 30s: %s", key, err))
 	}
 	return
 }
@@ -55,15 +102,96 @@ func New(version string) func() *schema.Provider {
 					DefaultFunc: schema.EnvDefaultFunc("BITLAUNCH_API_TOKEN", nil),
 					Description: "API Token",
 				},
+				"api_url": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_API_URL", defaultAPIURL),
+					ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+					Description:  "Base URL of the BitLaunch API, e.g. for a mirror or local stand-in. Can also be set with `BITLAUNCH_API_URL`.",
+				},
+				"http_timeout": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_HTTP_TIMEOUT", "60s"),
+					ValidateFunc: validateDuration,
+					Description:  "Timeout for each HTTP request to the API, as a duration like `30s`. Each retry gets the full timeout, and waiting between retries doesn't count towards it. Can also be set with `BITLAUNCH_HTTP_TIMEOUT`.",
+				},
+				"insecure_skip_verify": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("BITLAUNCH_INSECURE_SKIP_VERIFY", false),
+					Description: "Don't verify the API's TLS certificate. Can also be set with `BITLAUNCH_INSECURE_SKIP_VERIFY`.",
+				},
+				"ca_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("BITLAUNCH_CA_FILE", nil),
+					Description: "Path to a PEM bundle of extra CA certificates to trust for the API. Can also be set with `BITLAUNCH_CA_FILE`.",
+				},
+				"proxy_url": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_PROXY_URL", nil),
+					ValidateFunc: validation.IsURLWithScheme([]string{"http", "https", "socks5", "socks5h"}),
+					Description:  "HTTP, HTTPS or SOCKS5 proxy to send API requests through. Can also be set with `BITLAUNCH_PROXY_URL`. Defaults to the standard `HTTPS_PROXY`/`NO_PROXY` environment variables.",
+				},
+				"max_retries": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_MAX_RETRIES", defaultMaxRetries),
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "How many times to retry API calls that are rate limited or hit a server error. Only reads and deletes are retried automatically, and servers are only created again after checking the failed attempt didn't create one. Can also be set with `BITLAUNCH_MAX_RETRIES`.",
+				},
+				"retry_max_wait": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_RETRY_MAX_WAIT", defaultRetryMaxWait),
+					ValidateFunc: validateDuration,
+					Description:  "The longest to wait between retries, as a duration like `30s`, including when the API sends a `Retry-After` header. Can also be set with `BITLAUNCH_RETRY_MAX_WAIT`.",
+				},
+				"requests_per_second": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_REQUESTS_PER_SECOND", 0),
+					ValidateFunc: validation.FloatAtLeast(0),
+					Description:  "The most API calls to make per second, shared by all resources and data sources. Calls over the limit wait their turn, which doesn't count towards `http_timeout`. `0` means no limit. Can also be set with `BITLAUNCH_REQUESTS_PER_SECOND`.",
+				},
+				"max_hourly_spend": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_MAX_HOURLY_SPEND", 0),
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Fail the plan if the servers already running plus the new servers in it would cost more than this per hour, in the same units as a server's `rate`. New servers whose size isn't known until apply also fail the plan, as their cost can't be checked. `0` means no limit. Can also be set with `BITLAUNCH_MAX_HOURLY_SPEND`.",
+				},
+				"max_servers": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_MAX_SERVERS", 0),
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Fail the plan if the servers already running plus the new servers in it would be more than this. `0` means no limit. Can also be set with `BITLAUNCH_MAX_SERVERS`.",
+				},
+				"create_options_cache_ttl": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("BITLAUNCH_CREATE_OPTIONS_CACHE_TTL", defaultCreateOptionsCacheTTL),
+					ValidateFunc: validateDuration,
+					Description:  "How long to reuse each host's create options (images, sizes and regions) for, as a duration like `10m`. `0s` turns the cache off. Can also be set with `BITLAUNCH_CREATE_OPTIONS_CACHE_TTL`.",
+				},
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				"bitlaunch_sshkey": resourceSSHKey(),
-				"bitlaunch_server": resourceServer(),
+				"bitlaunch_sshkey":       resourceSSHKey(),
+				"bitlaunch_server":       resourceServer(),
+				"bitlaunch_server_ports": resourceServerPorts(),
 			},
 			DataSourcesMap: map[string]*schema.Resource{
-				"bitlaunch_size":   dataSourceSize(),
-				"bitlaunch_region": dataSourceRegion(),
-				"bitlaunch_image":  dataSourceImage(),
+				"bitlaunch_size":    dataSourceSize(),
+				"bitlaunch_sizes":   dataSourceSizes(),
+				"bitlaunch_region":  dataSourceRegion(),
+				"bitlaunch_regions": dataSourceRegions(),
+				"bitlaunch_image":   dataSourceImage(),
+				"bitlaunch_images":  dataSourceImages(),
+				"bitlaunch_hosts":   dataSourceHosts(),
+				"bitlaunch_account": dataSourceAccount(),
 			},
 		}
 
@@ -74,15 +202,42 @@ func New(version string) func() *schema.Provider {
 }
 
 type apiClient struct {
-	client *gobitlaunch.Client
+	client *bitlaunchClient
+	guard  *spendGuard
 }
 
 func configure(version string, p *schema.Provider) func(context.Context, *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	return func(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
 		token := data.Get("token").(string)
 
-		client := gobitlaunch.NewClient(token)
+		timeout, _ := time.ParseDuration(data.Get("http_timeout").(string))
+		httpClient, err := newHTTPClient(httpClientConfig{
+			Timeout:            timeout,
+			InsecureSkipVerify: data.Get("insecure_skip_verify").(bool),
+			CAFile:             data.Get("ca_file").(string),
+			ProxyURL:           data.Get("proxy_url").(string),
+		})
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+
+		retryMaxWait, _ := time.ParseDuration(data.Get("retry_max_wait").(string))
+		retry := retryPolicy{
+			MaxRetries: data.Get("max_retries").(int),
+			MinWait:    retryMinWait,
+			MaxWait:    retryMaxWait,
+		}
+
+		limiter := newRateLimiter(data.Get("requests_per_second").(float64))
+
+		client := newBitlaunchClient(token, data.Get("api_url").(string), httpClient, retry, limiter)
+		client.CreateOptions.cacheTTL, _ = time.ParseDuration(data.Get("create_options_cache_ttl").(string))
+
+		guard := &spendGuard{
+			MaxHourlySpend: data.Get("max_hourly_spend").(int),
+			MaxServers:     data.Get("max_servers").(int),
+		}
 
-		return &apiClient{client: client}, nil
+		return &apiClient{client: client, guard: guard}, nil
 	}
 }
@@ -1,28 +1,113 @@
 package tf_bitlaunch
 
 import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
-// providerFactories are used to instantiate a provider during acceptance testing.
-// The factory function will be invoked for every Terraform CLI command executed
-// to create a provider server to which the CLI can reattach.
-var providerFactories = map[string]func() (*schema.Provider, error){
-	"bitlaunch": func() (*schema.Provider, error) {
-		return New("dev")(), nil
-	},
-}
-
 func TestProvider(t *testing.T) {
 	if err := New("dev")().InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
 	}
 }
 
+func TestResolveHostID(t *testing.T) {
+	for host, want := range map[string]int{
+		"DigitalOcean": 0,
+		"digitalocean": 0,
+		"BITLAUNCH":    4,
+		"4":            4,
+		"9":            9,
+	} {
+		got, err := resolveHostID(host)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", host, err)
+		} else if got != want {
+			t.Errorf("%s: got %d, want %d", host, got, want)
+		}
+	}
+	for _, host := range []string{"", "Nope", "-1"} {
+		if _, err := resolveHostID(host); err == nil {
+			t.Errorf("%q: expected an error", host)
+		}
+	}
+
+	if !suppressEquivalentHost("host", "BitLaunch", "4", nil) {
+		t.Errorf("expected BitLaunch and 4 to be the same host")
+	}
+	if suppressEquivalentHost("host", "BitLaunch", "Vultr", nil) {
+		t.Errorf("expected BitLaunch and Vultr to be different hosts")
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	// You can add code here to run prior to any test case execution, for example assertions
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+// testConfigureProvider configures a provider with the raw config, returning its client
+func testConfigureProvider(t *testing.T, config map[string]interface{}) *apiClient {
+	t.Helper()
+	p := New("dev")()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(config)); diags.HasError() {
+		t.Fatalf("failed to configure provider: %v", diags)
+	}
+	return p.Meta().(*apiClient)
+}
+
+func TestProviderConfigureTLS(t *testing.T) {
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer api.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: api.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := testConfigureProvider(t, map[string]interface{}{"token": "token", "api_url": api.URL})
+	if _, err := client.client.CreateOptions.Show(4); err == nil {
+		t.Errorf("expected an untrusted certificate to fail")
+	}
+
+	client = testConfigureProvider(t, map[string]interface{}{"token": "token", "api_url": api.URL, "ca_file": caFile})
+	if _, err := client.client.CreateOptions.Show(4); err != nil {
+		t.Errorf("expected certificate from ca_file to be trusted: %s", err)
+	}
+
+	client = testConfigureProvider(t, map[string]interface{}{"token": "token", "api_url": api.URL, "insecure_skip_verify": true})
+	if _, err := client.client.CreateOptions.Show(4); err != nil {
+		t.Errorf("expected insecure_skip_verify to skip verification: %s", err)
+	}
+}
+
+func TestProviderConfigureProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("{}"))
+	}))
+	defer proxy.Close()
+
+	client := testConfigureProvider(t, map[string]interface{}{
+		"token":     "token",
+		"api_url":   "http://bitlaunch.invalid/api",
+		"proxy_url": proxy.URL,
+	})
+	if _, err := client.client.CreateOptions.Show(4); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if proxied != "http://bitlaunch.invalid/api/hosts-create-options/4" {
+		t.Errorf("expected request to go through the proxy, got %q", proxied)
+	}
+}
@@ -2,15 +2,24 @@ package tf_bitlaunch
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/bitlaunchio/gobitlaunch"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// The API can still report the old "ok" status straight after a resize or rebuild,
+// so give it time to change before polling
+var serverUpdateDelay = 10 * time.Second
+
 // https://developers.bitlaunch.io/reference/create-server
 func resourceServer() *schema.Resource {
 	return &schema.Resource{
@@ -18,32 +27,56 @@ func resourceServer() *schema.Resource {
 
 		CreateContext: resourceServerCreate,
 		ReadContext:   resourceServerRead,
+		UpdateContext: resourceServerUpdate,
 		DeleteContext: resourceServerDelete,
 
+		CustomizeDiff: resourceServerCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceServerImport,
+		},
+
 		Schema: map[string]*schema.Schema{
 			"host": {
-				Description: "The host for the server to reside on.",
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
+				Description:      "The host for the server to reside on. Either the host name (DigitalOcean, Vultr, etc.) or its numeric ID.",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     ValidateHostID,
+				DiffSuppressFunc: suppressEquivalentHost,
 			},
 			"name": {
-				Description: "The name of the server.",
+				Description: "The name of the server. The BitLaunch API can't rename servers, so changing this replaces the server. Use `display_name` or `tags` for labels that can change.",
 				Type:        schema.TypeString,
 				Required:    true,
 				ForceNew:    true,
 			},
+			"display_name": {
+				Description: "A label for the server that can be changed without replacing it. BitLaunch has nowhere to store it, so it's only kept in the Terraform state, and is empty after importing.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"tags": {
+				Description: "Labels for the server that can be changed without replacing it. Like `display_name`, these are only kept in the Terraform state.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 			"image_id": {
-				Description: "The image ID to use on the server.",
+				Description: "The image ID to use on the server. Changing this replaces the server, unless `rebuild_on_image_change` is set.",
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 			},
 			"size_id": {
-				Description: "The size ID of the server to be provisioned to.",
+				Description: "The size ID of the server to be provisioned to. Changing this resizes the server in place if the host supports it, otherwise the server is replaced.",
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 			},
 			"region_id": {
 				Description: "The region ID of the location that the server will reside at.",
@@ -52,29 +85,75 @@ func resourceServer() *schema.Resource {
 				ForceNew:    true,
 			},
 			"ssh_keys": {
-				Description: "An array of SSH key IDs to place on the server for authentication. Must be used if no password is designated of if the selected image does not support passwords.",
-				Type:        schema.TypeList,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				ForceNew:    true,
+				Description:      "An array of SSH key IDs to place on the server for authentication. Must be used if no password is designated of if the selected image does not support passwords. These can't be read back, so they're ignored for servers that were imported or created without them.",
+				Type:             schema.TypeList,
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				DiffSuppressFunc: suppressUnsetAfterCreate,
 			},
 			"password": {
-				Description: "The root user password to set on the server. Must be used if no SSH keys designated.",
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    true,
+				Description:      "The root user password to set on the server. Must be used if no SSH keys designated. Like `ssh_keys`, this is ignored for servers that were imported or created without it.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressUnsetAfterCreate,
 			},
 			"initscript": {
-				Description: "A script to run on first boot of the server. Only hosts with initScript enabled can use this feature.",
-				Type:        schema.TypeString,
+				Description:      "A script to run on first boot of the server. Only hosts with initScript enabled can use this feature. Like `ssh_keys`, this is ignored for servers that were imported or created without it.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressUnsetAfterCreate,
+			},
+			"deletion_protection": {
+				Description: "Refuse to destroy the server, including when a change would replace it. Set this to `false` and apply before destroying. BitLaunch's protection API is for DDoS protection, not deletes, so this is only checked by the provider and is kept in the Terraform state. Imported servers start with it off.",
+				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
+				Default:     false,
 			},
-			"wait_for_ip": {
-				Description: "Wait to get IP Address",
+			"restart_triggers": {
+				Description: "Arbitrary values that restart the server when any of them change, like `replace_triggered_by` but without replacing it.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"rebuild_on_image_change": {
+				Description: "Rebuild the server in place when `image_id` changes, keeping the same ID and IP address, instead of replacing it. Changes to `ssh_keys` or `initscript` still replace the server. Only used if the host supports rebuilding.",
 				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
+				Default:     false,
+			},
+			"wait_for_ip": {
+				Description:      "Wait to get IP Address",
+				Type:             schema.TypeBool,
+				Optional:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressAfterCreate,
+			},
+			"wait_for_port": {
+				Description:      "Wait until this port on `ipv4` accepts TCP connections, so provisioners don't try to connect before the server has booted. This also waits for the server to be ready, as `wait_for_ip` does. `0` turns this off. Defaults to `22`.",
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          22,
+				ForceNew:         true,
+				ValidateFunc:     validation.IsPortNumberOrZero,
+				DiffSuppressFunc: suppressAfterCreate,
+			},
+			"wait_for_ssh_banner": {
+				Description:      "When waiting for `wait_for_port`, also wait until it sends an SSH banner, so sshd is known to be answering.",
+				Type:             schema.TypeBool,
+				Optional:         true,
+				Default:          false,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressAfterCreate,
+			},
+			"wait_for_port_timeout": {
+				Description:      "How long to wait for `wait_for_port`, as a duration like `5m`. This is as well as waiting for the server to be created. Defaults to `5m`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "5m",
+				ForceNew:         true,
+				ValidateFunc:     validateDuration,
+				DiffSuppressFunc: suppressAfterCreate,
 			},
 			"ipv4": {
 				Description: "The name of the key.",
@@ -96,6 +175,11 @@ func resourceServer() *schema.Resource {
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			"backups": {
+				Description: "Whether the host's automatic backups are on for the server, which add to its `rate`. The BitLaunch API only reports this, so backups are turned on or off outside of Terraform.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
 			"rate": {
 				Description: "The hourly rate of the server that will be deducted from your account balance every hour.",
 				Type:        schema.TypeInt,
@@ -139,17 +223,237 @@ func setDataServer(data *schema.ResourceData, server *gobitlaunch.Server, hostNa
 	if err := data.Set("rate", server.Rate); err != nil {
 		return diag.FromErr(err)
 	}
+	if err := data.Set("backups", server.BackupsEnabled); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return diags
 }
 
+// suppressAfterCreate is for options only used during creation, so changing them,
+// or importing a server without them, doesn't replace the server
+func suppressAfterCreate(k, old, new string, data *schema.ResourceData) bool {
+	return data.Id() != ""
+}
+
+// suppressUnsetAfterCreate is for options only sent on creation that can't be read back.
+// If an existing server doesn't have them in its state, e.g. because it was imported,
+// what it was created with isn't known, so setting them isn't a reason to replace it.
+func suppressUnsetAfterCreate(k, old, new string, data *schema.ResourceData) bool {
+	if data.Id() == "" {
+		return false
+	}
+	// Lists are checked a key at a time, e.g. ssh_keys.# and ssh_keys.0
+	field := strings.SplitN(k, ".", 2)[0]
+	previous, _ := data.GetChange(field)
+	return isUnset(previous)
+}
+
+// isUnset checks if a string or list value from the state is empty
+func isUnset(value interface{}) bool {
+	switch value := value.(type) {
+	case string:
+		return value == ""
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// getSSHKeys gets the SSH Keys from the list
+func getSSHKeys(data *schema.ResourceData) []string {
+	sshKeysRaw := data.Get("ssh_keys").([]interface{})
+	sshKeys := make([]string, len(sshKeysRaw))
+	for i, raw := range sshKeysRaw {
+		sshKeys[i] = raw.(string)
+	}
+	return sshKeys
+}
+
+// serverCreateConfig is the config that has to match what the host offers.
+// Empty fields aren't checked, e.g. if they aren't known until apply.
+type serverCreateConfig struct {
+	ImageID    string
+	SizeID     string
+	RegionID   string
+	Password   string
+	InitScript string
+}
+
+// checkServerCreateOptions checks the image, size and region exist on the host and can
+// be used together, so mistakes are caught by plan instead of part way through apply
+func checkServerCreateOptions(ops *hostCreateOptions, hostName string, config serverCreateConfig) error {
+	var errs []error
+
+	var region *gobitlaunch.HostSubRegion
+	if config.RegionID != "" {
+		for i := range ops.Regions {
+			for _, subregion := range subregions(&ops.Regions[i]) {
+				if subregion.ID == config.RegionID {
+					region = &subregion
+				}
+			}
+		}
+		if region == nil {
+			errs = append(errs, fmt.Errorf("region_id %q isn't available on %s", config.RegionID, hostName))
+		}
+	}
+
+	if config.ImageID != "" {
+		image, version := findImageVersion(&ops.ServerCreateOptions, config.ImageID)
+		if version == nil {
+			errs = append(errs, fmt.Errorf("image_id %q isn't available on %s", config.ImageID, hostName))
+		} else {
+			if region != nil && (slices.Contains(image.UnavailableRegions, region.ID) || slices.Contains(image.UnavailableRegions, region.Slug)) {
+				errs = append(errs, fmt.Errorf("image %q (%s) isn't available in region %q", version.Description, config.ImageID, config.RegionID))
+			}
+			if config.Password != "" && version.PasswordUnsupported {
+				errs = append(errs, fmt.Errorf("image %q (%s) doesn't support passwords, use ssh_keys instead", version.Description, config.ImageID))
+			}
+		}
+	}
+
+	if config.SizeID != "" {
+		found := slices.ContainsFunc(ops.Sizes, func(size gobitlaunch.HostSize) bool { return size.ID == config.SizeID })
+		if !found {
+			errs = append(errs, fmt.Errorf("size_id %q isn't available on %s", config.SizeID, hostName))
+		} else if region != nil && slices.Contains(region.UnavailableSizes, config.SizeID) {
+			errs = append(errs, fmt.Errorf("size %q isn't available in region %q", config.SizeID, config.RegionID))
+		}
+	}
+
+	if config.InitScript != "" && !ops.HostOptions.Userscript {
+		errs = append(errs, fmt.Errorf("%s doesn't support initscript", hostName))
+	}
+
+	return errors.Join(errs...)
+}
+
+// knownString gets a string from the diff, or "" if it won't be known until apply
+func knownString(diff *schema.ResourceDiff, key string) string {
+	if !diff.NewValueKnown(key) {
+		return ""
+	}
+	return diff.Get(key).(string)
+}
+
+// changedString is knownString, but for existing servers is "" unless the key changed,
+// so what the server already has isn't checked against what the host offers now
+func changedString(diff *schema.ResourceDiff, key string) string {
+	if diff.Id() != "" && !diff.HasChange(key) {
+		return ""
+	}
+	return knownString(diff, key)
+}
+
+func resourceServerCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	client := meta.(*apiClient).client
+	guard := meta.(*apiClient).guard
+
+	planned := plannedServer{
+		Name:   knownString(diff, "name"),
+		SizeID: knownString(diff, "size_id"),
+	}
+	if !diff.NewValueKnown("host") {
+		if diff.Id() == "" {
+			return guard.plan(client, planned)
+		}
+		return nil
+	}
+	hostName := diff.Get("host").(string)
+	hostID, err := resolveHostID(hostName)
+	if err != nil {
+		return err
+	}
+
+	// Existing servers only have what changed checked again
+	if diff.Id() == "" || diff.HasChanges("image_id", "size_id", "region_id", "password", "initscript") {
+		ops, err := client.CreateOptions.Show(hostID)
+		if err != nil {
+			return err
+		}
+		err = checkServerCreateOptions(ops, hostName, serverCreateConfig{
+			ImageID:    changedString(diff, "image_id"),
+			SizeID:     changedString(diff, "size_id"),
+			RegionID:   changedString(diff, "region_id"),
+			Password:   changedString(diff, "password"),
+			InitScript: changedString(diff, "initscript"),
+		})
+		if err != nil {
+			return err
+		}
+		for _, size := range ops.Sizes {
+			if size.ID == planned.SizeID {
+				planned.CostPerHour = size.CostPerHour
+				planned.CostKnown = true
+			}
+		}
+	}
+
+	// Nothing to update in place on new servers
+	if diff.Id() == "" {
+		return guard.plan(client, planned)
+	}
+
+	rebuild := false
+	if diff.HasChange("image_id") {
+		if diff.Get("rebuild_on_image_change").(bool) {
+			ops, err := client.CreateOptions.Show(hostID)
+			if err != nil {
+				return err
+			}
+			rebuild = ops.HostOptions.Rebuild
+		}
+		if !rebuild {
+			tflog.Debug(ctx, "not rebuilding, server will be replaced")
+			if err := diff.ForceNew("image_id"); err != nil {
+				return err
+			}
+		}
+	}
+	// These are only sent on creation, and aren't known for imported servers
+	for _, key := range []string{"ssh_keys", "initscript"} {
+		previous, _ := diff.GetChange(key)
+		if diff.HasChange(key) && !isUnset(previous) {
+			if err := diff.ForceNew(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if diff.HasChange("size_id") {
+		ops, err := client.CreateOptions.Show(hostID)
+		if err != nil {
+			return err
+		}
+		if !ops.HostOptions.Resize {
+			tflog.Debug(ctx, "host doesn't support resizing, server will be replaced")
+			if err := diff.ForceNew("size_id"); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The API works out the new rate when the change is made
+	if diff.HasChange("size_id") {
+		if err := diff.SetNewComputed("rate"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func resourceServerCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	client := meta.(*apiClient).client
 	tflog.Trace(ctx, "Creating an server")
 
 	hostName := data.Get("host").(string)
-	hostID := HostIDs[hostName]
+	hostID, err := resolveHostID(hostName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	server := gobitlaunch.CreateServerOptions{
 		HostID:      hostID,
@@ -159,12 +463,7 @@ func resourceServerCreate(ctx context.Context, data *schema.ResourceData, meta i
 		RegionID:    data.Get("region_id").(string),
 	}
 
-	// Get SSH Keys from list
-	sshKeysRaw := data.Get("ssh_keys").([]interface{})
-	sshKeys := make([]string, len(sshKeysRaw))
-	for i, raw := range sshKeysRaw {
-		sshKeys[i] = raw.(string)
-	}
+	sshKeys := getSSHKeys(data)
 	if len(sshKeys) > 0 {
 		server.SSHKeys = sshKeys
 	}
@@ -178,37 +477,35 @@ func resourceServerCreate(ctx context.Context, data *schema.ResourceData, meta i
 		server.InitScript = initScript
 	}
 
-	newServer, err := client.Server.Create(&server)
+	newServer, err := client.Server.Create(ctx, &server)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	if data.Get("wait_for_ip").(bool) {
-		// Poll until server.Status == "ok"
-		maxTime := time.Now().Add(60 * time.Second)
-		for {
-			if time.Now().After(maxTime) {
-				return diag.Errorf("Timed out getting IPv4 address")
-			}
-
-			newServer, err = client.Server.Show(newServer.ID)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			if newServer.Status == "ok" {
-				// Server IPv4 should now be there?
-				break
-			}
-			if newServer.Status == "error" || newServer.Status == "stopped" {
-				return diag.Errorf("Server creation returned error status")
-			}
-			time.Sleep(1 * time.Second)
+	// Waiting for the port needs the server to be ready too
+	port := data.Get("wait_for_port").(int)
+	if data.Get("wait_for_ip").(bool) || port != 0 {
+		newServer, err = waitForServerOK(ctx, client, newServer.ID, 0, data.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
 	setDataServer(data, newServer, hostName)
 	tflog.Trace(ctx, fmt.Sprintf("created Server %s", newServer.ID))
 
+	// The server is in the state by now, so it's tainted rather than lost if this fails
+	if port != 0 {
+		if newServer.Ipv4 == "" {
+			return diag.Errorf("server %s doesn't have an ipv4 address to wait for port %d on", newServer.ID, port)
+		}
+		timeout, _ := time.ParseDuration(data.Get("wait_for_port_timeout").(string))
+		if err := waitForPort(ctx, newServer.Ipv4, port, data.Get("wait_for_ssh_banner").(bool), timeout); err != nil {
+			return diag.FromErr(err)
+		}
+		tflog.Trace(ctx, fmt.Sprintf("Server %s is accepting connections on port %d", newServer.ID, port))
+	}
+
 	return diags
 }
 
@@ -219,20 +516,102 @@ func resourceServerRead(ctx context.Context, data *schema.ResourceData, meta int
 
 	tflog.Trace(ctx, "Reading a server")
 
-	servers, err := client.Server.List()
+	server, err := client.Server.Show(data.Id())
+	if serverGone(server, err) {
+		tflog.Trace(ctx, "server not found")
+		data.SetId("")
+		return diags
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	for _, server := range servers {
-		if server.ID == data.Id() {
-			setDataServer(data, &server, hostName)
-			return diags
+
+	// display_name, tags, deletion_protection and restart_triggers aren't in the API, so they're left as they are in the state
+	return setDataServer(data, server, hostName)
+}
+
+func resourceServerUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Updating a server")
+
+	// display_name, tags, deletion_protection and restart_triggers are only in the state, which the SDK saves for us
+
+	if data.HasChange("image_id") {
+		// The diff only gets here without replacing if rebuilding is enabled and supported
+		imageID := data.Get("image_id").(string)
+		hostID, err := resolveHostID(data.Get("host").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		ops, err := client.CreateOptions.Show(hostID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		opts := gobitlaunch.RebuildOptions{ID: imageID}
+		if _, version := findImageVersion(&ops.ServerCreateOptions, imageID); version != nil {
+			opts.Description = version.Description
+		}
+
+		if err := client.Server.Rebuild(data.Id(), &opts); err != nil {
+			return diag.FromErr(err)
+		}
+		if _, err := waitForServerOK(ctx, client, data.Id(), serverUpdateDelay, data.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.FromErr(err)
+		}
+		tflog.Trace(ctx, fmt.Sprintf("rebuilt Server %s with image %s", data.Id(), imageID))
+	}
+
+	if data.HasChange("size_id") {
+		sizeID := data.Get("size_id").(string)
+		if err := client.Server.Resize(data.Id(), sizeID); err != nil {
+			return diag.FromErr(err)
+		}
+		if _, err := waitForServerOK(ctx, client, data.Id(), serverUpdateDelay, data.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.FromErr(err)
 		}
+		tflog.Trace(ctx, fmt.Sprintf("resized Server %s to %s", data.Id(), sizeID))
 	}
 
-	tflog.Trace(ctx, "server not found")
-	data.SetId("")
-	return diags
+	if data.HasChange("restart_triggers") {
+		if err := client.Server.Restart(data.Id()); err != nil {
+			return diag.FromErr(err)
+		}
+		if _, err := waitForServerOK(ctx, client, data.Id(), serverUpdateDelay, data.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.FromErr(err)
+		}
+		tflog.Trace(ctx, fmt.Sprintf("restarted Server %s", data.Id()))
+	}
+
+	return resourceServerRead(ctx, data, meta)
+}
+
+func resourceServerImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Importing a server")
+
+	server, err := client.Server.Show(data.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields that aren't in the API are set to their defaults, as if the config didn't set them
+	for key, field := range resourceServer().Schema {
+		if field.Default == nil {
+			continue
+		}
+		if err := data.Set(key, field.Default); err != nil {
+			return nil, err
+		}
+	}
+
+	// The host isn't in the config yet, so work it out from the API data
+	hostName := hostNameFromID(server.HostID)
+
+	if diags := setDataServer(data, server, hostName); diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+
+	return []*schema.ResourceData{data}, nil
 }
 
 func resourceServerDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -240,10 +619,18 @@ func resourceServerDelete(ctx context.Context, data *schema.ResourceData, meta i
 	client := meta.(*apiClient).client
 	tflog.Trace(ctx, "Deleting a server")
 
+	if data.Get("deletion_protection").(bool) {
+		return diag.Errorf("server %s has deletion_protection set, set it to false and apply before destroying it", data.Id())
+	}
+
 	err := client.Server.Destroy(data.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	if err := waitForServerDeleted(ctx, client, data.Id(), data.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return diags
 }
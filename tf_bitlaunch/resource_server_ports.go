@@ -0,0 +1,291 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// The protection proxy region gobitlaunch uses
+const defaultProtectionRegion = "bvm-lux"
+
+// The API takes every port in a range on its own, so this keeps a wide range like
+// 1-65535 from becoming one huge request
+const maxProtectionPorts = 1000
+
+var portProtocols = []string{"tcp", "udp"}
+
+// https://developers.bitlaunch.io/reference/server-object
+func resourceServerPorts() *schema.Resource {
+	return &schema.Resource{
+		Description: "The ports a server accepts traffic on. BitLaunch filters ports with its DDoS protection proxy, so this turns protection on for the server, and destroying it turns protection off again. Traffic has to go through the proxy's `proxy_ip` to be filtered.",
+
+		CreateContext: resourceServerPortsCreate,
+		ReadContext:   resourceServerPortsRead,
+		UpdateContext: resourceServerPortsUpdate,
+		DeleteContext: resourceServerPortsDelete,
+
+		CustomizeDiff: resourceServerPortsCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"server_id": {
+				Description: "The ID of the server.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"protection_region": {
+				Description: "The region of the protection proxy. Changing this replaces the resource.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     defaultProtectionRegion,
+				ForceNew:    true,
+			},
+			"port": {
+				Description: "A port, or range of ports, to allow. Any port not listed is blocked. At most 1000 ports can be allowed, counting each port in a range.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"protocol": {
+							Description:  "Either `tcp` or `udp`.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(portProtocols, false),
+						},
+						"from_port": {
+							Description:  "The port, or the first port of the range.",
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IsPortNumber,
+						},
+						"to_port": {
+							Description:  "The last port of the range, if it's a range.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IsPortNumber,
+						},
+					},
+				},
+			},
+			"proxy_ip": {
+				Description: "The IP address of the protection proxy, which traffic to the server has to go through to be filtered.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// expandPorts turns port blocks into the single ports the API takes, sorted and without duplicates.
+// It fails if there are more than maxProtectionPorts.
+func expandPorts(raw []interface{}) ([]gobitlaunch.Ports, error) {
+	seen := map[gobitlaunch.Ports]bool{}
+	ports := []gobitlaunch.Ports{}
+	for _, r := range raw {
+		m := r.(map[string]interface{})
+		protocol := m["protocol"].(string)
+		from := m["from_port"].(int)
+		to := m["to_port"].(int)
+		if to == 0 {
+			to = from
+		}
+		if to < from {
+			return nil, fmt.Errorf("%s port range %d-%d ends before it starts", protocol, from, to)
+		}
+		for number := from; number <= to; number++ {
+			port := gobitlaunch.Ports{PortNumber: number, Protocol: protocol}
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+			if len(ports) > maxProtectionPorts {
+				return nil, fmt.Errorf("port blocks allow more than %d ports, use fewer or smaller ranges", maxProtectionPorts)
+			}
+		}
+	}
+	sortPorts(ports)
+	return ports, nil
+}
+
+func sortPorts(ports []gobitlaunch.Ports) {
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		return ports[i].PortNumber < ports[j].PortNumber
+	})
+}
+
+// flattenPorts turns the API's ports back into blocks, joining consecutive ports into ranges
+func flattenPorts(ports []gobitlaunch.Ports) []interface{} {
+	sorted := append([]gobitlaunch.Ports{}, ports...)
+	sortPorts(sorted)
+
+	blocks := []interface{}{}
+	for i := 0; i < len(sorted); {
+		start := sorted[i]
+		end := start
+		for i++; i < len(sorted) && sorted[i].Protocol == start.Protocol && sorted[i].PortNumber <= end.PortNumber+1; i++ {
+			end = sorted[i]
+		}
+		block := map[string]interface{}{
+			"protocol":  start.Protocol,
+			"from_port": start.PortNumber,
+			"to_port":   0,
+		}
+		if end.PortNumber != start.PortNumber {
+			block["to_port"] = end.PortNumber
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// samePorts checks two sorted lists of ports are the same
+func samePorts(a, b []gobitlaunch.Ports) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func resourceServerPortsCustomizeDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("port") {
+		return nil
+	}
+	_, err := expandPorts(diff.Get("port").(*schema.Set).List())
+	return err
+}
+
+func resourceServerPortsCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*apiClient).client
+	serverID := data.Get("server_id").(string)
+	tflog.Trace(ctx, "Setting server ports")
+
+	ports, err := expandPorts(data.Get("port").(*schema.Set).List())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server, err := client.Server.Show(serverID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !server.Protection.Enabled {
+		if _, err := client.Server.Protection(serverID, true, data.Get("protection_region").(string)); err != nil {
+			return diag.FromErr(err)
+		}
+		tflog.Trace(ctx, fmt.Sprintf("turned on protection for Server %s", serverID))
+	}
+	// Protection is billed, so if setting the ports fails, the resource is kept to be
+	// tainted and destroyed, which turns it off again
+	data.SetId(serverID)
+	if _, err := client.Server.SetPorts(serverID, ports); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceServerPortsRead(ctx, data, meta)
+}
+
+func resourceServerPortsRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Reading server ports")
+
+	server, err := client.Server.Show(data.Id())
+	if serverGone(server, err) {
+		tflog.Trace(ctx, "server not found")
+		data.SetId("")
+		return diags
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := data.Set("server_id", server.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	// Without protection every port is open, which is drift from any config
+	ports := []gobitlaunch.Ports{}
+	proxyIP := ""
+	if server.Protection.Enabled {
+		ports = append(ports, server.Protection.Proxy.Ports...)
+		sortPorts(ports)
+		proxyIP = server.Protection.Proxy.IP
+		if server.Protection.Proxy.Region != "" {
+			if err := data.Set("protection_region", server.Protection.Proxy.Region); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	}
+	if err := data.Set("proxy_ip", proxyIP); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Keep the blocks as they were written if they still allow the same ports,
+	// e.g. 80 and 81 rather than 80-81
+	current, err := expandPorts(data.Get("port").(*schema.Set).List())
+	if err == nil && server.Protection.Enabled && samePorts(current, ports) {
+		return diags
+	}
+	if err := data.Set("port", flattenPorts(ports)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceServerPortsUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Updating server ports")
+
+	ports, err := expandPorts(data.Get("port").(*schema.Set).List())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Protection may have been turned off outside of Terraform
+	server, err := client.Server.Show(data.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !server.Protection.Enabled {
+		if _, err := client.Server.Protection(data.Id(), true, data.Get("protection_region").(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if _, err := client.Server.SetPorts(data.Id(), ports); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceServerPortsRead(ctx, data, meta)
+}
+
+func resourceServerPortsDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Turning off server protection")
+
+	_, err := client.Server.Protection(data.Id(), false, "")
+	if err != nil && !isNotFound(err) {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
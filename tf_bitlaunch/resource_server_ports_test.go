@@ -0,0 +1,328 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccResourceBitlaunchServerPorts(t *testing.T) {
+	api := newMockAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: api.providerFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccResourceBitlaunchServerPorts, 443),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("bitlaunch_server_ports.ports", "server_id", "bitlaunch_server.server", "id"),
+					resource.TestCheckResourceAttr("bitlaunch_server_ports.ports", "port.#", "2"),
+					resource.TestCheckResourceAttrSet("bitlaunch_server_ports.ports", "proxy_ip"),
+				),
+			},
+			{
+				// Change the range in place
+				Config: fmt.Sprintf(testAccResourceBitlaunchServerPorts, 8443),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("bitlaunch_server_ports.ports", "port.*", map[string]string{
+						"protocol":  "tcp",
+						"from_port": "80",
+						"to_port":   "8443",
+					}),
+				),
+			},
+			{
+				ResourceName:      "bitlaunch_server_ports.ports",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccResourceBitlaunchServerPorts = `
+resource "bitlaunch_server" "server" {
+  host        = "BitLaunch"
+  name        = "tf-acc-server-ports"
+  image_id    = "10000"
+  size_id     = "nibble-1024"
+  region_id   = "lon1"
+  wait_for_ip = true
+
+  # There's nothing listening on the mock's addresses
+  wait_for_port = 0
+}
+
+resource "bitlaunch_server_ports" "ports" {
+  server_id = bitlaunch_server.server.id
+
+  port {
+    protocol  = "tcp"
+    from_port = 22
+  }
+
+  port {
+    protocol  = "tcp"
+    from_port = 80
+    to_port   = %d
+  }
+}
+`
+
+func TestExpandFlattenPorts(t *testing.T) {
+	raw := []interface{}{
+		map[string]interface{}{"protocol": "udp", "from_port": 53, "to_port": 0},
+		map[string]interface{}{"protocol": "tcp", "from_port": 80, "to_port": 82},
+		map[string]interface{}{"protocol": "tcp", "from_port": 81, "to_port": 0},
+		map[string]interface{}{"protocol": "tcp", "from_port": 22, "to_port": 0},
+	}
+	ports, err := expandPorts(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []gobitlaunch.Ports{
+		{PortNumber: 22, Protocol: "tcp"},
+		{PortNumber: 80, Protocol: "tcp"},
+		{PortNumber: 81, Protocol: "tcp"},
+		{PortNumber: 82, Protocol: "tcp"},
+		{PortNumber: 53, Protocol: "udp"},
+	}
+	if !reflect.DeepEqual(ports, expected) {
+		t.Errorf("expected %v, got %v", expected, ports)
+	}
+
+	blocks := flattenPorts(ports)
+	expectedBlocks := []interface{}{
+		map[string]interface{}{"protocol": "tcp", "from_port": 22, "to_port": 0},
+		map[string]interface{}{"protocol": "tcp", "from_port": 80, "to_port": 82},
+		map[string]interface{}{"protocol": "udp", "from_port": 53, "to_port": 0},
+	}
+	if !reflect.DeepEqual(blocks, expectedBlocks) {
+		t.Errorf("expected %v, got %v", expectedBlocks, blocks)
+	}
+
+	_, err = expandPorts([]interface{}{map[string]interface{}{"protocol": "tcp", "from_port": 443, "to_port": 80}})
+	if err == nil || !strings.Contains(err.Error(), "ends before it starts") {
+		t.Errorf("expected backwards range to fail, got %v", err)
+	}
+
+	// Up to the limit is fine, and duplicates don't count towards it
+	ports, err = expandPorts([]interface{}{
+		map[string]interface{}{"protocol": "udp", "from_port": 1, "to_port": maxProtectionPorts},
+		map[string]interface{}{"protocol": "udp", "from_port": 1, "to_port": 0},
+	})
+	if err != nil || len(ports) != maxProtectionPorts {
+		t.Errorf("expected %d ports, got %d and %v", maxProtectionPorts, len(ports), err)
+	}
+	_, err = expandPorts([]interface{}{
+		map[string]interface{}{"protocol": "udp", "from_port": 1, "to_port": maxProtectionPorts},
+		map[string]interface{}{"protocol": "tcp", "from_port": 22, "to_port": 0},
+	})
+	if err == nil || !strings.Contains(err.Error(), "more than 1000 ports") {
+		t.Errorf("expected going over the port limit to fail, got %v", err)
+	}
+}
+
+func TestResourceServerPortsValidation(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+
+	for name, test := range map[string]struct {
+		port map[string]interface{}
+		err  string
+	}{
+		"port too high":    {port: map[string]interface{}{"protocol": "tcp", "from_port": 70000}, err: "port.0.from_port"},
+		"port zero":        {port: map[string]interface{}{"protocol": "tcp", "from_port": 0}, err: "port.0.from_port"},
+		"unknown protocol": {port: map[string]interface{}{"protocol": "icmp", "from_port": 22}, err: "port.0.protocol"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"server_id": "1",
+				"port":      []interface{}{test.port},
+			})
+			diags := resourceServerPorts().Validate(config)
+			if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), test.err) {
+				t.Errorf("expected error about %s, got %v", test.err, diags)
+			}
+		})
+	}
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"server_id": "1",
+		"port":      []interface{}{map[string]interface{}{"protocol": "tcp", "from_port": 443, "to_port": 80}},
+	})
+	_, err := resourceServerPorts().SimpleDiff(context.Background(), nil, config, meta)
+	if err == nil || !strings.Contains(err.Error(), "ends before it starts") {
+		t.Errorf("expected plan to fail for a backwards range, got %v", err)
+	}
+
+	config = terraform.NewResourceConfigRaw(map[string]interface{}{
+		"server_id": "1",
+		"port":      []interface{}{map[string]interface{}{"protocol": "tcp", "from_port": 1, "to_port": 65535}},
+	})
+	_, err = resourceServerPorts().SimpleDiff(context.Background(), nil, config, meta)
+	if err == nil || !strings.Contains(err.Error(), "more than 1000 ports") {
+		t.Errorf("expected plan to fail for every port, got %v", err)
+	}
+}
+
+func TestResourceServerPorts(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	id := api.addServer(testServer())
+
+	data := schema.TestResourceDataRaw(t, resourceServerPorts().Schema, map[string]interface{}{
+		"server_id": id,
+		"port": []interface{}{
+			map[string]interface{}{"protocol": "tcp", "from_port": 22},
+			map[string]interface{}{"protocol": "tcp", "from_port": 80},
+			map[string]interface{}{"protocol": "tcp", "from_port": 81},
+		},
+	})
+	if diags := resourceServerPortsCreate(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	server, err := meta.client.Server.Show(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !server.Protection.Enabled || server.Protection.Proxy.Region != defaultProtectionRegion {
+		t.Errorf("expected protection to be turned on in %s, got %+v", defaultProtectionRegion, server.Protection)
+	}
+	if len(server.Protection.Proxy.Ports) != 3 {
+		t.Errorf("expected 3 ports, got %v", server.Protection.Proxy.Ports)
+	}
+	if ip := data.Get("proxy_ip").(string); ip == "" {
+		t.Errorf("expected a proxy_ip")
+	}
+	// The same ports as configured, so the blocks are kept as they were written
+	if n := data.Get("port").(*schema.Set).Len(); n != 3 {
+		t.Errorf("expected the 3 configured port blocks, got %d", n)
+	}
+
+	// Changed outside of Terraform
+	api.mu.Lock()
+	api.servers[id].Protection.Proxy.Ports = append(api.servers[id].Protection.Proxy.Ports, gobitlaunch.Ports{PortNumber: 3306, Protocol: "tcp"})
+	api.mu.Unlock()
+	if diags := resourceServerPortsRead(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	ports, _ := expandPorts(data.Get("port").(*schema.Set).List())
+	if len(ports) != 4 || ports[3].PortNumber != 3306 {
+		t.Errorf("expected read to find port 3306, got %v", ports)
+	}
+
+	// Protection turned off outside of Terraform
+	if _, err := meta.client.Server.Protection(id, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if diags := resourceServerPortsRead(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if n := data.Get("port").(*schema.Set).Len(); n != 0 {
+		t.Errorf("expected no ports without protection, got %d", n)
+	}
+
+	if diags := resourceServerPortsDelete(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	server, err = meta.client.Server.Show(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.Protection.Enabled {
+		t.Errorf("expected protection to be turned off")
+	}
+
+	// The server is already gone
+	destroyed := testServer()
+	destroyed.Status = "destroyed"
+	for _, id := range []string{"missing", api.addServer(destroyed)} {
+		data.SetId(id)
+		if diags := resourceServerPortsRead(context.Background(), data, meta); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if data.Id() != "" {
+			t.Errorf("expected server %s to be removed from state, got ID %q", id, data.Id())
+		}
+	}
+}
+
+func TestResourceServerPortsUpdate(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	id := api.addServer(testServer())
+	raw := map[string]interface{}{
+		"server_id": id,
+		"port":      []interface{}{map[string]interface{}{"protocol": "tcp", "from_port": 22}},
+	}
+
+	data := schema.TestResourceDataRaw(t, resourceServerPorts().Schema, raw)
+	if diags := resourceServerPortsCreate(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	raw["port"] = []interface{}{
+		map[string]interface{}{"protocol": "tcp", "from_port": 22},
+		map[string]interface{}{"protocol": "udp", "from_port": 60000, "to_port": 60010},
+	}
+	diff, err := resourceServerPorts().SimpleDiff(context.Background(), data.State(), terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Fatalf("expected ports to be updated in place")
+	}
+	if _, diags := resourceServerPorts().Apply(context.Background(), data.State(), diff, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	server, err := meta.client.Server.Show(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(server.Protection.Proxy.Ports) != 12 {
+		t.Errorf("expected 12 ports, got %v", server.Protection.Proxy.Ports)
+	}
+	if calls := api.callCount("POST /servers/" + id + "/protection"); calls != 1 {
+		t.Errorf("expected protection to only be turned on once, got %d calls", calls)
+	}
+}
+
+func TestResourceServerPortsCreateFailed(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	id := api.addServer(testServer())
+
+	api.failNext("POST /servers/"+id+"/protection/ports", mockFailure{StatusCode: http.StatusBadRequest})
+	data := schema.TestResourceDataRaw(t, resourceServerPorts().Schema, map[string]interface{}{
+		"server_id": id,
+		"port":      []interface{}{map[string]interface{}{"protocol": "tcp", "from_port": 22}},
+	})
+	if diags := resourceServerPortsCreate(context.Background(), data, meta); !diags.HasError() {
+		t.Fatalf("expected setting the ports to fail")
+	}
+	// Protection was turned on, so it has to be in the state to be turned off again
+	if data.Id() != id {
+		t.Errorf("expected the resource to be kept in the state, got ID %q", data.Id())
+	}
+	if diags := resourceServerPortsDelete(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	server, err := meta.client.Server.Show(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.Protection.Enabled {
+		t.Errorf("expected protection to be turned off")
+	}
+}
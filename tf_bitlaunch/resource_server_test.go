@@ -1,37 +1,648 @@
 package tf_bitlaunch
 
 import (
+	"context"
+	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/bitlaunchio/gobitlaunch"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
+// testAccCheckServerID saves the server ID, or if already saved, checks it hasn't changed
+func testAccCheckServerID(name string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+		if *id == "" {
+			*id = rs.Primary.ID
+		} else if *id != rs.Primary.ID {
+			return fmt.Errorf("server was replaced: ID changed from %s to %s", *id, rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
 func TestAccResourceBitlaunchServer(t *testing.T) {
-	t.Skip("resource not yet implemented, remove this once you add your own code")
+	var serverID string
+	api := newMockAPI(t)
 
-	resource.UnitTest(t, resource.TestCase{
+	resource.Test(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
+		ProviderFactories: api.providerFactories(),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceBitlaunchServer,
+				Config: fmt.Sprintf(testAccResourceBitlaunchServer, "Ubuntu", 1, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServerID("bitlaunch_server.server", &serverID),
+					resource.TestCheckResourceAttr("bitlaunch_server.server", "host", "BitLaunch"),
+					resource.TestCheckResourceAttr("bitlaunch_server.server", "name", "tf-acc-server"),
+					resource.TestMatchResourceAttr("bitlaunch_server.server", "status", regexp.MustCompile("^ok$")),
+				),
+			},
+			{
+				// Resize in place
+				Config: fmt.Sprintf(testAccResourceBitlaunchServer, "Ubuntu", 2, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServerID("bitlaunch_server.server", &serverID),
+					resource.TestCheckResourceAttrPair("bitlaunch_server.server", "size_id", "data.bitlaunch_size.size", "id"),
+				),
+			},
+			{
+				// Rebuild in place
+				Config: fmt.Sprintf(testAccResourceBitlaunchServer, "Debian", 2, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServerID("bitlaunch_server.server", &serverID),
+					resource.TestCheckResourceAttrPair("bitlaunch_server.server", "image_id", "data.bitlaunch_image.image", "id"),
+				),
+			},
+			{
+				// Back to the default, which is what importing sets
+				Config: fmt.Sprintf(testAccResourceBitlaunchServer, "Debian", 2, false),
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"bitlaunch_sshkey.sshkey", "name", regexp.MustCompile("^ba")),
+					testAccCheckServerID("bitlaunch_server.server", &serverID),
+					resource.TestCheckResourceAttr("bitlaunch_server.server", "rebuild_on_image_change", "false"),
 				),
 			},
+			{
+				ResourceName:      "bitlaunch_server.server",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// These are only sent on creation and can't be read back
+				ImportStateVerifyIgnore: []string{"password", "ssh_keys", "initscript", "wait_for_ip", "wait_for_port", "wait_for_ssh_banner", "wait_for_port_timeout"},
+			},
+			{
+				// Replace the state with the imported one, then make sure it doesn't cause a diff
+				ResourceName:       "bitlaunch_server.server",
+				ImportState:        true,
+				ImportStatePersist: true,
+			},
+			{
+				Config:   fmt.Sprintf(testAccResourceBitlaunchServer, "Debian", 2, false),
+				PlanOnly: true,
+			},
 		},
 	})
 }
 
 const testAccResourceBitlaunchServer = `
-provider "bitlaunch" {
-	token = "aaa"
-  }
+data "bitlaunch_image" "image" {
+  host        = "BitLaunch"
+  distro_name = %q
+}
+
+data "bitlaunch_region" "region" {
+  host        = "BitLaunch"
+  region_name = "London"
+}
+
+data "bitlaunch_size" "size" {
+  host      = "BitLaunch"
+  cpu_count = %d
+}
 
 resource "bitlaunch_sshkey" "sshkey" {
-	name    = "tf_sshkeys"
-	content = "aaaa"
-  }  
+  name    = "tf-acc-server"
+  content = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDk9G3MJCIcLh5YvqkZbRrq495Zg9rw9gQo9mSE8aw1K tf-acc"
+}
+
+resource "bitlaunch_server" "server" {
+  host        = "BitLaunch"
+  name        = "tf-acc-server"
+  image_id    = data.bitlaunch_image.image.id
+  size_id     = data.bitlaunch_size.size.id
+  region_id   = data.bitlaunch_region.region.id
+  ssh_keys    = [bitlaunch_sshkey.sshkey.id]
+  wait_for_ip = true
+
+  # There's nothing listening on the mock's addresses
+  wait_for_port = 0
+
+  rebuild_on_image_change = %t
+}
 `
+
+func TestResourceServerRead(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	server := testServer()
+	server.Ipv4 = "10.0.0.1"
+	id := api.addServer(server)
+
+	data := schema.TestResourceDataRaw(t, resourceServer().Schema, map[string]interface{}{"host": "BitLaunch"})
+	data.SetId(id)
+	if diags := resourceServerRead(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if data.Id() != id {
+		t.Errorf("expected server to stay in state, got ID %q", data.Id())
+	}
+	if ip := data.Get("ipv4").(string); ip != "10.0.0.1" {
+		t.Errorf("expected ipv4 10.0.0.1, got %q", ip)
+	}
+
+	data.SetId("missing")
+	if diags := resourceServerRead(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if data.Id() != "" {
+		t.Errorf("expected missing server to be removed from state, got ID %q", data.Id())
+	}
+
+	// Destroyed outside of Terraform, but still shown for a while
+	destroyed := testServer()
+	destroyed.Status = "destroyed"
+	data.SetId(api.addServer(destroyed))
+	if diags := resourceServerRead(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if data.Id() != "" {
+		t.Errorf("expected destroyed server to be removed from state, got ID %q", data.Id())
+	}
+
+	if calls := api.callCount("GET /servers"); calls != 0 {
+		t.Errorf("expected reads to not list servers, got %d list calls", calls)
+	}
+}
+
+func TestResourceServerCreateDelete(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+
+	data := schema.TestResourceDataRaw(t, resourceServer().Schema, map[string]interface{}{
+		"host":        "BitLaunch",
+		"name":        "web",
+		"image_id":    "10000",
+		"size_id":     "nibble-1024",
+		"region_id":   "lon1",
+		"wait_for_ip": true,
+		// There's nothing listening on the mock's addresses
+		"wait_for_port": 0,
+	})
+	if diags := resourceServerCreate(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if status := data.Get("status").(string); status != "ok" {
+		t.Errorf("expected status ok, got %q", status)
+	}
+	if ip := data.Get("ipv4").(string); ip == "" {
+		t.Errorf("expected an ipv4 address")
+	}
+	if desc := data.Get("image_description").(string); desc != "Ubuntu 18.04 LTS" {
+		t.Errorf("expected image description from create options, got %q", desc)
+	}
+
+	if diags := resourceServerDelete(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, err := meta.client.Server.Show(data.Id()); !isNotFound(err) {
+		t.Errorf("expected server to be deleted, got %v", err)
+	}
+}
+
+func TestCheckServerCreateOptions(t *testing.T) {
+	api := newMockAPI(t)
+	client := api.client().client
+	bitlaunch, err := client.CreateOptions.Show(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digitalOcean, err := client.CreateOptions.Show(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		ops    *hostCreateOptions
+		config serverCreateConfig
+		err    string
+	}{
+		"valid": {
+			ops:    bitlaunch,
+			config: serverCreateConfig{ImageID: "10000", SizeID: "nibble-1024", RegionID: "lon1"},
+		},
+		"unknown until apply": {
+			ops:    bitlaunch,
+			config: serverCreateConfig{},
+		},
+		"missing image": {
+			ops:    bitlaunch,
+			config: serverCreateConfig{ImageID: "1", SizeID: "nibble-1024", RegionID: "lon1"},
+			err:    `image_id "1" isn't available on BitLaunch`,
+		},
+		"missing size": {
+			ops:    bitlaunch,
+			config: serverCreateConfig{ImageID: "10000", SizeID: "s-1vcpu-1gb", RegionID: "lon1"},
+			err:    `size_id "s-1vcpu-1gb" isn't available on BitLaunch`,
+		},
+		"missing region": {
+			ops:    bitlaunch,
+			config: serverCreateConfig{ImageID: "10000", SizeID: "nibble-1024", RegionID: "nyc1"},
+			err:    `region_id "nyc1" isn't available on BitLaunch`,
+		},
+		"size not in region": {
+			ops:    bitlaunch,
+			config: serverCreateConfig{ImageID: "10000", SizeID: "nibble-2048", RegionID: "ams1"},
+			err:    `size "nibble-2048" isn't available in region "ams1"`,
+		},
+		"image not in region": {
+			ops:    digitalOcean,
+			config: serverCreateConfig{ImageID: "106427349", SizeID: "s-1vcpu-1gb", RegionID: "sfo1"},
+			err:    `image "21.10 x64" \(106427349\) isn't available in region "sfo1"`,
+		},
+		"image in subregion": {
+			ops:    digitalOcean,
+			config: serverCreateConfig{ImageID: "106427349", SizeID: "s-1vcpu-1gb", RegionID: "nyc3"},
+		},
+		"password unsupported": {
+			ops:    digitalOcean,
+			config: serverCreateConfig{ImageID: "76400437", Password: "hunter2"},
+			err:    `doesn't support passwords`,
+		},
+		"initscript unsupported": {
+			ops:    bitlaunch,
+			config: serverCreateConfig{ImageID: "10000", InitScript: "#!/bin/sh"},
+			err:    `BitLaunch doesn't support initscript`,
+		},
+		"initscript supported": {
+			ops:    digitalOcean,
+			config: serverCreateConfig{ImageID: "106427349", InitScript: "#!/bin/sh"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hostName := hostNameFromID(test.ops.HostID)
+			err := checkServerCreateOptions(test.ops, hostName, test.config)
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+			} else if err == nil || !regexp.MustCompile(test.err).MatchString(err.Error()) {
+				t.Errorf("expected error matching %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestResourceServerImport(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	key, err := meta.client.SSHKey.Create(&gobitlaunch.SSHKey{Name: "deploy", Content: testSSHKey(t, "", "deploy").Content})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := api.addServer(testServer())
+
+	// Only the ID is known when importing
+	data := resourceServer().Data(&terraform.InstanceState{ID: id})
+	if _, err := resourceServerImport(context.Background(), data, meta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Otherwise a config that doesn't set them would show a change
+	for _, key := range []string{"rebuild_on_image_change", "wait_for_port", "wait_for_port_timeout"} {
+		if _, ok := data.State().Attributes[key]; !ok {
+			t.Errorf("expected %s to be set to its default", key)
+		}
+	}
+
+	// What the server was created with can't be read back, so it isn't replaced
+	diff, err := resourceServer().SimpleDiff(context.Background(), data.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":      "BitLaunch",
+		"name":      "web",
+		"image_id":  "10000",
+		"size_id":   "nibble-1024",
+		"region_id": "lon1",
+		"ssh_keys":  []interface{}{key.ID},
+		"password":  "hunter2",
+	}), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff != nil {
+		if diff.RequiresNew() {
+			t.Errorf("expected the imported server not to be replaced, got %v", diff)
+		}
+		for k := range diff.Attributes {
+			if strings.HasPrefix(k, "ssh_keys") || k == "password" {
+				t.Errorf("expected no change to %s, got %v", k, diff.Attributes[k])
+			}
+		}
+	}
+
+	// Servers created with them still get replaced when they change
+	raw := map[string]interface{}{
+		"host":      "BitLaunch",
+		"name":      "web",
+		"image_id":  "10000",
+		"size_id":   "nibble-1024",
+		"region_id": "lon1",
+		"ssh_keys":  []interface{}{key.ID},
+		// There's nothing listening on the mock's addresses
+		"wait_for_port": 0,
+	}
+	created := schema.TestResourceDataRaw(t, resourceServer().Schema, raw)
+	if diags := resourceServerCreate(context.Background(), created, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	raw["ssh_keys"] = []interface{}{"other"}
+	diff, err = resourceServer().SimpleDiff(context.Background(), created.State(), terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Errorf("expected changing ssh_keys to replace the server")
+	}
+
+	// Even when rebuilding, as the rebuild only takes the image
+	raw["rebuild_on_image_change"] = true
+	raw["image_id"] = "10001"
+	diff, err = resourceServer().SimpleDiff(context.Background(), created.State(), terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Errorf("expected changing ssh_keys to replace the server when rebuilding")
+	}
+}
+
+func TestResourceServerResizeRetiredImage(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	server := testServer()
+	server.Image = "retired-image"
+	id := api.addServer(server)
+
+	data := resourceServer().Data(&terraform.InstanceState{ID: id})
+	if _, err := resourceServerImport(context.Background(), data, meta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	raw := map[string]interface{}{
+		"host":      "BitLaunch",
+		"name":      "web",
+		"image_id":  "retired-image",
+		"size_id":   "nibble-2048",
+		"region_id": "lon1",
+	}
+	// Only the new size is checked against what the host offers
+	state := testApplyServer(t, data.State(), raw, meta)
+	if state.Attributes["size_id"] != "nibble-2048" {
+		t.Errorf("expected the server to be resized, got %s", state.Attributes["size_id"])
+	}
+
+	raw["size_id"] = "missing"
+	_, err := resourceServer().SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err == nil || !strings.Contains(err.Error(), `size_id "missing" isn't available`) || strings.Contains(err.Error(), "image_id") {
+		t.Errorf("expected only the size to fail, got %v", err)
+	}
+}
+
+func TestResourceServerLabels(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	raw := map[string]interface{}{
+		"host":         "BitLaunch",
+		"name":         "web",
+		"image_id":     "10000",
+		"size_id":      "nibble-1024",
+		"region_id":    "lon1",
+		"display_name": "Web server",
+		"tags":         []interface{}{"prod"},
+		// There's nothing listening on the mock's addresses
+		"wait_for_port": 0,
+	}
+
+	data := schema.TestResourceDataRaw(t, resourceServer().Schema, raw)
+	if diags := resourceServerCreate(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if diags := resourceServerRead(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if name := data.Get("display_name").(string); name != "Web server" {
+		t.Errorf("expected display_name to be kept by read, got %q", name)
+	}
+	state := data.State()
+
+	// Changing labels is done in place
+	raw["display_name"] = "Website"
+	raw["tags"] = []interface{}{"prod", "web"}
+	diff, err := resourceServer().SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Errorf("expected changing labels not to replace the server")
+	}
+	if _, ok := diff.Attributes["display_name"]; !ok {
+		t.Errorf("expected display_name to change")
+	}
+
+	// The API can't rename servers
+	raw["name"] = "website"
+	diff, err = resourceServer().SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !diff.RequiresNew() {
+		t.Errorf("expected changing name to replace the server")
+	}
+}
+
+func TestResourceServerDeletionProtection(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	id := api.addServer(testServer())
+
+	data := schema.TestResourceDataRaw(t, resourceServer().Schema, map[string]interface{}{
+		"host":                "BitLaunch",
+		"deletion_protection": true,
+	})
+	data.SetId(id)
+	diags := resourceServerDelete(context.Background(), data, meta)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "deletion_protection") {
+		t.Fatalf("expected deletion_protection error, got %v", diags)
+	}
+	if calls := api.callCount("DELETE /servers/" + id); calls != 0 {
+		t.Errorf("expected protected server not to be destroyed, got %d delete calls", calls)
+	}
+
+	if err := data.Set("deletion_protection", false); err != nil {
+		t.Fatal(err)
+	}
+	if diags := resourceServerDelete(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, err := meta.client.Server.Show(id); !isNotFound(err) {
+		t.Errorf("expected server to be deleted, got %v", err)
+	}
+
+	// It isn't in the API, so imported servers get the default
+	id = api.addServer(testServer())
+	imported := resourceServer().Data(&terraform.InstanceState{ID: id})
+	if _, err := resourceServerImport(context.Background(), imported, meta); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if value, ok := imported.State().Attributes["deletion_protection"]; !ok || value != "false" {
+		t.Errorf("expected deletion_protection to be false after import, got %q", value)
+	}
+	diff, err := resourceServer().SimpleDiff(context.Background(), imported.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":                "BitLaunch",
+		"name":                "web",
+		"image_id":            "10000",
+		"size_id":             "nibble-1024",
+		"region_id":           "lon1",
+		"deletion_protection": false,
+	}), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff != nil && len(diff.Attributes) > 0 {
+		t.Errorf("expected no changes after import, got %v", diff.Attributes)
+	}
+}
+
+// testApplyServer plans and applies a config to an existing server, like terraform apply would
+func testApplyServer(t *testing.T, state *terraform.InstanceState, raw map[string]interface{}, meta *apiClient) *terraform.InstanceState {
+	t.Helper()
+	diff, err := resourceServer().SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Fatalf("expected the server to be updated in place")
+	}
+	state, diags := resourceServer().Apply(context.Background(), state, diff, meta)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	return state
+}
+
+func TestResourceServerRestartTriggers(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+	raw := map[string]interface{}{
+		"host":             "BitLaunch",
+		"name":             "web",
+		"image_id":         "10000",
+		"size_id":          "nibble-1024",
+		"region_id":        "lon1",
+		"restart_triggers": map[string]interface{}{"config": "1"},
+		// There's nothing listening on the mock's addresses
+		"wait_for_port": 0,
+	}
+
+	data := schema.TestResourceDataRaw(t, resourceServer().Schema, raw)
+	if diags := resourceServerCreate(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	id := data.Id()
+
+	// Other changes don't restart the server
+	raw["display_name"] = "Web server"
+	state := testApplyServer(t, data.State(), raw, meta)
+	if calls := api.callCount("POST /servers/" + id + "/restart"); calls != 0 {
+		t.Errorf("expected no restart calls, got %d", calls)
+	}
+
+	raw["restart_triggers"] = map[string]interface{}{"config": "2"}
+	state = testApplyServer(t, state, raw, meta)
+	if calls := api.callCount("POST /servers/" + id + "/restart"); calls != 1 {
+		t.Errorf("expected 1 restart call, got %d", calls)
+	}
+	if status := state.Attributes["status"]; status != "ok" {
+		t.Errorf("expected server to be ok after restarting, got %q", status)
+	}
+}
+
+func TestResourceServerBackups(t *testing.T) {
+	api := newMockAPI(t)
+	meta := api.client()
+
+	// Turned on outside of Terraform
+	server := testServer()
+	server.BackupsEnabled = true
+	id := api.addServer(server)
+	data := schema.TestResourceDataRaw(t, resourceServer().Schema, map[string]interface{}{"host": "BitLaunch"})
+	data.SetId(id)
+	if diags := resourceServerRead(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if !data.Get("backups").(bool) {
+		t.Errorf("expected read to find backups on")
+	}
+
+	// The API has no way to change them
+	diags := resourceServer().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"host":      "BitLaunch",
+		"name":      "web",
+		"image_id":  "10000",
+		"size_id":   "nibble-1024",
+		"region_id": "lon1",
+		"backups":   true,
+	}))
+	if !diags.HasError() {
+		t.Errorf("expected backups not to be settable")
+	}
+}
+
+func TestResourceServerWaitForPort(t *testing.T) {
+	api := newMockAPI(t)
+	api.ipv4 = "127.0.0.1"
+	meta := api.client()
+	raw := map[string]interface{}{
+		"host":                  "BitLaunch",
+		"name":                  "web",
+		"image_id":              "10000",
+		"size_id":               "nibble-1024",
+		"region_id":             "lon1",
+		"wait_for_ip":           true,
+		"wait_for_port":         testListener(t, "SSH-2.0-OpenSSH_9.6\r\n"),
+		"wait_for_ssh_banner":   true,
+		"wait_for_port_timeout": "1s",
+	}
+
+	data := schema.TestResourceDataRaw(t, resourceServer().Schema, raw)
+	if diags := resourceServerCreate(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// The port is still waited for without wait_for_ip, which needs the server to be ready
+	delete(raw, "wait_for_ip")
+	data = schema.TestResourceDataRaw(t, resourceServer().Schema, raw)
+	if diags := resourceServerCreate(context.Background(), data, meta); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if status := data.Get("status").(string); status != "ok" {
+		t.Errorf("expected the server to be waited for, got status %q", status)
+	}
+	raw["wait_for_ip"] = true
+
+	// The server was still created, so it stays in the state to be tainted
+	raw["wait_for_port"] = testClosedPort(t)
+	raw["wait_for_port_timeout"] = "100ms"
+	data = schema.TestResourceDataRaw(t, resourceServer().Schema, raw)
+	diags := resourceServerCreate(context.Background(), data, meta)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "to accept connections") {
+		t.Fatalf("expected timeout waiting for port, got %v", diags)
+	}
+	if data.Id() == "" {
+		t.Errorf("expected the server to be kept in the state")
+	}
+
+	// Changing how to wait doesn't replace existing servers
+	state := data.State()
+	raw["wait_for_port"] = 2222
+	diff, err := resourceServer().SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff != nil && diff.RequiresNew() {
+		t.Errorf("expected wait_for_port not to replace the server")
+	}
+}
@@ -3,8 +3,11 @@ package tf_bitlaunch
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"golang.org/x/crypto/ssh"
+
 	"github.com/bitlaunchio/gobitlaunch"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -20,6 +23,10 @@ func resourceSSHKey() *schema.Resource {
 		ReadContext:   resourceSSHKeyRead,
 		DeleteContext: resourceSSHKeyDelete,
 
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSSHKeyImport,
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Description: "The name of the key.",
@@ -109,6 +116,88 @@ func resourceSSHKeyRead(ctx context.Context, data *schema.ResourceData, meta int
 	return diags
 }
 
+// findSSHKey resolves an import ID to a single key. The ID can be the key ID,
+// "name:<name>", or "fingerprint:<md5 or sha256 fingerprint>"
+func findSSHKey(keys []gobitlaunch.SSHKey, importID string) (*gobitlaunch.SSHKey, error) {
+	var match func(key *gobitlaunch.SSHKey) bool
+	switch {
+	case strings.HasPrefix(importID, "name:"):
+		name := strings.TrimPrefix(importID, "name:")
+		match = func(key *gobitlaunch.SSHKey) bool { return key.Name == name }
+	case strings.HasPrefix(importID, "fingerprint:"):
+		fingerprint := strings.TrimPrefix(importID, "fingerprint:")
+		match = func(key *gobitlaunch.SSHKey) bool { return sshKeyHasFingerprint(key, fingerprint) }
+	default:
+		match = func(key *gobitlaunch.SSHKey) bool { return key.ID == importID }
+	}
+
+	var found []gobitlaunch.SSHKey
+	for _, key := range keys {
+		if match(&key) {
+			found = append(found, key)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no SSH key found matching %q", importID)
+	}
+	if len(found) > 1 {
+		ids := make([]string, len(found))
+		for i, key := range found {
+			ids[i] = key.ID
+		}
+		return nil, fmt.Errorf("%q matches %d SSH keys (%s), import one by ID instead", importID, len(found), strings.Join(ids, ", "))
+	}
+	return &found[0], nil
+}
+
+// sshKeyHasFingerprint checks the fingerprint the API reports, as well as the
+// MD5 and SHA256 fingerprints of the key content, as the API only gives one of them
+func sshKeyHasFingerprint(key *gobitlaunch.SSHKey, fingerprint string) bool {
+	fingerprint = strings.TrimSpace(fingerprint)
+	// MD5 fingerprints are hex, so case and the optional prefix don't matter
+	md5 := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(fingerprint, "MD5:"), "md5:"))
+	sha256 := fingerprint
+	if !strings.HasPrefix(sha256, "SHA256:") {
+		sha256 = "SHA256:" + sha256
+	}
+
+	candidates := []string{key.Fingerprint}
+	if pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.Content)); err == nil {
+		candidates = append(candidates, ssh.FingerprintLegacyMD5(pubKey), ssh.FingerprintSHA256(pubKey))
+	}
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if candidate == fingerprint || candidate == sha256 || strings.ToLower(candidate) == md5 {
+			return true
+		}
+	}
+	return false
+}
+
+func resourceSSHKeyImport(ctx context.Context, data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*apiClient).client
+	tflog.Trace(ctx, "Importing an sshKey")
+
+	keys, err := client.SSHKey.List()
+	if err != nil {
+		return nil, err
+	}
+	key, err := findSSHKey(keys, data.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	data.SetId(key.ID)
+	if diags := setDataSSHKey(data, key); diags.HasError() {
+		return nil, fmt.Errorf("%s", diags[0].Summary)
+	}
+
+	return []*schema.ResourceData{data}, nil
+}
+
 func resourceSSHKeyDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	client := meta.(*apiClient).client
@@ -1,37 +1,119 @@
 package tf_bitlaunch
 
 import (
-	"regexp"
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
 	"testing"
 
+	"github.com/bitlaunchio/gobitlaunch"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestAccResourceBitlaunchSSHKey(t *testing.T) {
-	t.Skip("resource not yet implemented, remove this once you add your own code")
+	api := newMockAPI(t)
 
-	resource.UnitTest(t, resource.TestCase{
+	resource.Test(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: providerFactories,
+		ProviderFactories: api.providerFactories(),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccResourceBitlaunchSSHKey,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestMatchResourceAttr(
-						"bitlaunch_sshkey.sshkey", "name", regexp.MustCompile("^ba")),
+					resource.TestCheckResourceAttr("bitlaunch_sshkey.sshkey", "name", "tf-acc-sshkey"),
+					resource.TestCheckResourceAttrSet("bitlaunch_sshkey.sshkey", "fingerprint"),
 				),
 			},
+			{
+				ResourceName:      "bitlaunch_sshkey.sshkey",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "bitlaunch_sshkey.sshkey",
+				ImportState:       true,
+				ImportStateId:     "name:tf-acc-sshkey",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName: "bitlaunch_sshkey.sshkey",
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return "fingerprint:" + s.RootModule().Resources["bitlaunch_sshkey.sshkey"].Primary.Attributes["fingerprint"], nil
+				},
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
 
 const testAccResourceBitlaunchSSHKey = `
-provider "bitlaunch" {
-	token = "aaa"
-  }
-
 resource "bitlaunch_sshkey" "sshkey" {
-	name    = "tf_sshkeys"
-	content = "aaaa"
-  }  
+  name    = "tf-acc-sshkey"
+  content = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDk9G3MJCIcLh5YvqkZbRrq495Zg9rw9gQo9mSE8aw1K tf-acc"
+}
 `
+
+func testSSHKey(t *testing.T, id string, name string) gobitlaunch.SSHKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gobitlaunch.SSHKey{
+		ID:          id,
+		Name:        name,
+		Fingerprint: ssh.FingerprintLegacyMD5(sshPub),
+		Content:     strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))),
+	}
+}
+
+func TestFindSSHKey(t *testing.T) {
+	keys := []gobitlaunch.SSHKey{
+		testSSHKey(t, "1", "laptop"),
+		testSSHKey(t, "2", "ci"),
+		testSSHKey(t, "3", "ci"),
+	}
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(keys[0].Content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha256 := ssh.FingerprintSHA256(pubKey)
+
+	cases := []struct {
+		importID string
+		wantID   string
+		wantErr  string
+	}{
+		{importID: "2", wantID: "2"},
+		{importID: "name:laptop", wantID: "1"},
+		{importID: "fingerprint:" + keys[0].Fingerprint, wantID: "1"},
+		{importID: "fingerprint:MD5:" + strings.ToUpper(keys[0].Fingerprint), wantID: "1"},
+		{importID: "fingerprint:" + sha256, wantID: "1"},
+		{importID: "fingerprint:" + strings.TrimPrefix(sha256, "SHA256:"), wantID: "1"},
+		{importID: "name:ci", wantErr: "matches 2 SSH keys"},
+		{importID: "name:missing", wantErr: "no SSH key found"},
+		{importID: "4", wantErr: "no SSH key found"},
+	}
+	for _, c := range cases {
+		key, err := findSSHKey(keys, c.importID)
+		if c.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", c.importID, c.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.importID, err)
+			continue
+		}
+		if key.ID != c.wantID {
+			t.Errorf("%s: expected key %s, got %s", c.importID, c.wantID, key.ID)
+		}
+	}
+}
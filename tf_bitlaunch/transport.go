@@ -0,0 +1,203 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// retryPolicy is how many times, and how long between, failed API calls are retried
+type retryPolicy struct {
+	MaxRetries int
+	MinWait    time.Duration
+	MaxWait    time.Duration
+}
+
+// backoff gets how long to wait before the next attempt. A Retry-After header wins over
+// exponential backoff, but is still capped at MaxWait.
+func (p retryPolicy) backoff(attempt int, retryAfter string) time.Duration {
+	wait := time.Duration(float64(p.MinWait) * math.Pow(2, float64(attempt)))
+	if after, ok := parseRetryAfter(retryAfter); ok {
+		wait = after
+	}
+	if wait > p.MaxWait {
+		wait = p.MaxWait
+	}
+	return wait
+}
+
+// parseRetryAfter parses a Retry-After header, which is either seconds or an HTTP date
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// isRetryableStatus is for rate limits and server errors that may go away on their own
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || (statusCode >= 500 && statusCode != http.StatusNotImplemented)
+}
+
+// isRetryableNetworkError is for errors where the request may work if sent again,
+// which isn't the case for cancellations or certificate problems
+func isRetryableNetworkError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &certErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// isIdempotent is for requests that are safe to send again if we don't know whether they worked
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sleepContext waits, or returns early if the context is cancelled
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryTransport retries idempotent requests that hit rate limits, server errors or
+// network errors. Other requests are sent once, and it's up to the caller to work out
+// if they can be retried safely.
+type retryTransport struct {
+	next   http.RoundTripper
+	policy retryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req.Method) {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, errors.New("can't retry request with a body that can't be re-read")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if err != nil && (req.Context().Err() != nil || !isRetryableNetworkError(err)) {
+			return nil, err
+		}
+		if attempt >= t.policy.MaxRetries {
+			return resp, err
+		}
+
+		var retryAfter string
+		if resp != nil {
+			retryAfter = resp.Header.Get("Retry-After")
+		}
+		wait := t.policy.backoff(attempt, retryAfter)
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// timeoutTransport gives each attempt at a request its own timeout, which lasts until
+// the response body is closed. It's used instead of http.Client.Timeout, which would
+// also count the time spent waiting between retries.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnCloseBody cancels the context of a request once its response body is closed
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// rateLimitTransport makes every request wait for the limiter, which is shared by all
+// resources and data sources, so parallel operations don't trip the API's rate limits.
+// It sits under retryTransport, so retries are limited too, but over timeoutTransport,
+// so waiting for the limiter doesn't count towards http_timeout.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// newRateLimiter creates a token bucket limiter, or nil for no limit. The bucket only
+// holds one token, so requests are evenly spaced rather than sent in bursts.
+func newRateLimiter(requestsPerSecond float64) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), 1)
+}
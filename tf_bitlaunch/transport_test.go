@@ -0,0 +1,151 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = retryPolicy{MaxRetries: 2, MinWait: time.Millisecond, MaxWait: 10 * time.Millisecond}
+
+func TestRetryTransport(t *testing.T) {
+	api := newMockAPI(t)
+	client := newBitlaunchClient(mockToken, api.URL, api.Server.Client(), testRetryPolicy, nil)
+
+	// Reads are retried
+	api.failNext("GET /hosts-create-options/4", mockFailure{StatusCode: http.StatusBadGateway})
+	api.failNext("GET /hosts-create-options/4", mockFailure{StatusCode: http.StatusTooManyRequests, RetryAfter: "0"})
+	if _, err := client.CreateOptions.Show(4); err != nil {
+		t.Errorf("expected read to be retried until it worked: %s", err)
+	}
+	if calls := api.callCount("GET /hosts-create-options/4"); calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+
+	// Until they run out of retries
+	for i := 0; i < 3; i++ {
+		api.failNext("GET /servers", mockFailure{StatusCode: http.StatusServiceUnavailable})
+	}
+	var apiErr *apiError
+	if _, err := client.Server.List(); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the last error to be returned, got %v", err)
+	}
+	if calls := api.callCount("GET /servers"); calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+
+	// Errors that won't go away aren't retried
+	if _, err := client.Server.Show("missing"); !isNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if calls := api.callCount("GET /servers/missing"); calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls)
+	}
+
+	// Non-idempotent calls aren't retried by the transport
+	id := api.addServer(testServer())
+	api.failNext("POST /servers/"+id+"/resize", mockFailure{StatusCode: http.StatusBadGateway})
+	if err := client.Server.Resize(id, "nibble-2048"); err == nil {
+		t.Errorf("expected resize to fail")
+	}
+	if calls := api.callCount("POST /servers/" + id + "/resize"); calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{MaxRetries: 5, MinWait: 100 * time.Millisecond, MaxWait: 5 * time.Second}
+
+	cases := []struct {
+		attempt    int
+		retryAfter string
+		want       time.Duration
+	}{
+		{attempt: 0, want: 100 * time.Millisecond},
+		{attempt: 3, want: 800 * time.Millisecond},
+		{attempt: 10, want: 5 * time.Second},
+		{attempt: 0, retryAfter: "2", want: 2 * time.Second},
+		{attempt: 0, retryAfter: "120", want: 5 * time.Second},
+		{attempt: 1, retryAfter: "soon", want: 200 * time.Millisecond},
+		{attempt: 0, retryAfter: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0},
+	}
+	for _, c := range cases {
+		if got := policy.backoff(c.attempt, c.retryAfter); got != c.want {
+			t.Errorf("backoff(%d, %q): expected %s, got %s", c.attempt, c.retryAfter, c.want, got)
+		}
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	api := newMockAPI(t)
+	client := newBitlaunchClient(mockToken, api.URL, api.Server.Client(), retryPolicy{}, newRateLimiter(20))
+
+	// 10 requests at 20 per second should take at least 450ms, however many goroutines send them
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Server.List(); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(started); elapsed < 450*time.Millisecond {
+		t.Errorf("expected requests to be throttled, took %s", elapsed)
+	}
+	if calls := api.callCount("GET /servers"); calls != 10 {
+		t.Errorf("expected all 10 requests to be sent, got %d", calls)
+	}
+
+	// Waiting for the limiter is longer than the timeout, but isn't part of the request
+	httpClient := api.Server.Client()
+	httpClient.Timeout = 300 * time.Millisecond
+	client = newBitlaunchClient(mockToken, api.URL, httpClient, retryPolicy{}, newRateLimiter(2))
+	for i := 0; i < 3; i++ {
+		if _, err := client.Server.List(); err != nil {
+			t.Errorf("expected request %d to wait for the limiter: %s", i, err)
+		}
+	}
+
+	if newRateLimiter(0) != nil {
+		t.Errorf("expected no limiter when requests_per_second is 0")
+	}
+}
+
+func TestTimeoutTransport(t *testing.T) {
+	api := newMockAPI(t)
+	httpClient := api.Server.Client()
+	httpClient.Timeout = 200 * time.Millisecond
+	client := newBitlaunchClient(mockToken, api.URL, httpClient, retryPolicy{MaxRetries: 2, MinWait: 300 * time.Millisecond, MaxWait: time.Second}, nil)
+
+	// Waiting to retry takes longer than the timeout, but isn't part of any attempt
+	api.failNext("GET /servers", mockFailure{StatusCode: http.StatusTooManyRequests})
+	api.failNext("GET /servers", mockFailure{StatusCode: http.StatusTooManyRequests, RetryAfter: "1"})
+	if _, err := client.Server.List(); err != nil {
+		t.Errorf("expected each retry to get its own timeout: %s", err)
+	}
+	if calls := api.callCount("GET /servers"); calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+
+	// A slow attempt still times out
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	client = newBitlaunchClient(mockToken, slow.URL, httpClient, retryPolicy{}, nil)
+	if _, err := client.Server.List(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the request to time out, got %v", err)
+	}
+}
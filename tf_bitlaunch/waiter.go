@@ -0,0 +1,139 @@
+package tf_bitlaunch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitlaunchio/gobitlaunch"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+)
+
+// How often to try connecting to a server's port, and how long each attempt can take
+var (
+	portWaitInterval = 5 * time.Second
+	portDialTimeout  = 10 * time.Second
+)
+
+// Server statuses seen while a server is being created or changed
+var serverPendingStatuses = []string{"pending", "creating", "building", "rebuilding", "resizing", "restarting", "starting"}
+
+// Server statuses seen while waiting for a server to be deleted
+var serverDeletingStatuses = []string{"ok", "stopped", "error", "pending", "destroying", "deleting"}
+
+// serverGone checks if the result of showing a server means it no longer exists, as
+// some hosts keep destroyed servers around for a while
+func serverGone(server *gobitlaunch.Server, err error) bool {
+	if err != nil {
+		return isNotFound(err)
+	}
+	return server.Status == "destroyed"
+}
+
+// serverStateRefreshFunc gets the current server status. A missing or destroyed
+// server is reported as not found, so a waiter with no target states can wait for it to go.
+func serverStateRefreshFunc(client *bitlaunchClient, id string) retry.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		server, err := client.Server.Show(id)
+		if serverGone(server, err) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if server.Status == "error" && server.ErrorText != "" {
+			return server, server.Status, fmt.Errorf("server %s returned error status: %s", id, server.ErrorText)
+		}
+		return server, server.Status, nil
+	}
+}
+
+// waitForServerState waits for a server to go from one of the pending statuses to one
+// of the target statuses. The delay between polls backs off exponentially, and the
+// wait stops if the context is cancelled.
+func waitForServerState(ctx context.Context, client *bitlaunchClient, id string, pending []string, target []string, delay time.Duration, timeout time.Duration) (*gobitlaunch.Server, error) {
+	conf := &retry.StateChangeConf{
+		Pending:    pending,
+		Target:     target,
+		Refresh:    serverStateRefreshFunc(client, id),
+		Delay:      delay,
+		Timeout:    timeout,
+		MinTimeout: 1 * time.Second,
+	}
+
+	raw, err := conf.WaitForStateContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for server %s to be %s: %w", id, strings.Join(target, ", "), err)
+	}
+	server, _ := raw.(*gobitlaunch.Server)
+	return server, nil
+}
+
+// waitForServerOK waits for the server to be ready after being created or changed
+func waitForServerOK(ctx context.Context, client *bitlaunchClient, id string, delay time.Duration, timeout time.Duration) (*gobitlaunch.Server, error) {
+	return waitForServerState(ctx, client, id, serverPendingStatuses, []string{"ok"}, delay, timeout)
+}
+
+// waitForServerDeleted waits until the server no longer appears
+func waitForServerDeleted(ctx context.Context, client *bitlaunchClient, id string, timeout time.Duration) error {
+	conf := &retry.StateChangeConf{
+		Pending:    serverDeletingStatuses,
+		Target:     []string{},
+		Refresh:    serverStateRefreshFunc(client, id),
+		Timeout:    timeout,
+		MinTimeout: 1 * time.Second,
+	}
+
+	if _, err := conf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("error waiting for server %s to be deleted: %w", id, err)
+	}
+	return nil
+}
+
+// checkPort connects to the address, and if banner is set, checks an SSH server is answering
+func checkPort(ctx context.Context, address string, banner bool) error {
+	dialer := net.Dialer{Timeout: portDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if !banner {
+		return nil
+	}
+
+	// Servers can send other lines before the banner, see RFC 4253 section 4.2
+	conn.SetReadDeadline(time.Now().Add(portDialTimeout))
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if strings.HasPrefix(line, "SSH-") {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("no SSH banner from %s: %w", address, err)
+		}
+	}
+}
+
+// waitForPort waits until the port accepts connections, or with banner set, until sshd is
+// answering on it. The status can be ok before the server has finished booting.
+func waitForPort(ctx context.Context, host string, port int, banner bool, timeout time.Duration) error {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		err := checkPort(ctx, address, banner)
+		if err == nil {
+			return nil
+		}
+		if sleepErr := sleepContext(ctx, portWaitInterval); sleepErr != nil {
+			return fmt.Errorf("error waiting for %s to accept connections: %w", address, err)
+		}
+	}
+}
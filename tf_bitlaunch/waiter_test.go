@@ -0,0 +1,192 @@
+package tf_bitlaunch
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForServerState(t *testing.T) {
+	api := newMockAPI(t)
+	client := api.client().client
+	ctx := context.Background()
+
+	// The mock finishes building the next time the server is read
+	pending := testServer()
+	pending.Status = "pending"
+	id := api.addServer(pending)
+	server, err := waitForServerOK(ctx, client, id, 0, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if server.ID != id || server.Status != "ok" {
+		t.Errorf("expected server %s to be ok, got %s %q", id, server.ID, server.Status)
+	}
+
+	failed := testServer()
+	failed.Status = "error"
+	failed.ErrorText = "no capacity in lon1"
+	id = api.addServer(failed)
+	_, err = waitForServerOK(ctx, client, id, 0, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "no capacity in lon1") {
+		t.Errorf("expected the server's error, got %v", err)
+	}
+
+	stopped := testServer()
+	stopped.Status = "stopped"
+	id = api.addServer(stopped)
+	_, err = waitForServerOK(ctx, client, id, 0, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "unexpected state 'stopped'") {
+		t.Errorf("expected an unexpected state error, got %v", err)
+	}
+
+	// Stays ok, so never gets to the target
+	id = api.addServer(testServer())
+	_, err = waitForServerState(ctx, client, id, []string{"ok"}, []string{"stopped"}, 0, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := waitForServerState(cancelled, client, id, []string{"ok"}, []string{"stopped"}, 0, time.Minute); err == nil {
+		t.Errorf("expected an error once cancelled")
+	}
+
+	_, err = waitForServerOK(ctx, client, "missing", 0, 100*time.Millisecond)
+	if err == nil {
+		t.Errorf("expected an error for a missing server")
+	}
+}
+
+func TestWaitForServerDeleted(t *testing.T) {
+	api := newMockAPI(t)
+	client := api.client().client
+	ctx := context.Background()
+
+	id := api.addServer(testServer())
+	if err := client.Server.Destroy(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitForServerDeleted(ctx, client, id, time.Minute); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// Some hosts keep destroyed servers around for a while
+	destroyed := testServer()
+	destroyed.Status = "destroyed"
+	id = api.addServer(destroyed)
+	if err := waitForServerDeleted(ctx, client, id, time.Minute); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	id = api.addServer(testServer())
+	err := waitForServerDeleted(ctx, client, id, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "to be deleted") {
+		t.Errorf("expected a timeout waiting for the server, got %v", err)
+	}
+}
+
+// testListener listens on a local port, sending each connection the greeting before
+// closing it, and returns the port
+func testListener(t *testing.T, greeting string) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte(greeting))
+			conn.Close()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// testClosedPort gets a local port that nothing is listening on
+func testClosedPort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
+
+func TestWaitForPort(t *testing.T) {
+	ctx := context.Background()
+
+	sshd := testListener(t, "Welcome\r\nSSH-2.0-OpenSSH_9.6\r\n")
+	if err := waitForPort(ctx, "127.0.0.1", sshd, true, time.Second); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// Open, but not SSH
+	web := testListener(t, "HTTP/1.1 400 Bad Request\r\n\r\n")
+	if err := waitForPort(ctx, "127.0.0.1", web, false, time.Second); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := waitForPort(ctx, "127.0.0.1", web, true, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "no SSH banner") {
+		t.Errorf("expected no SSH banner error, got %v", err)
+	}
+
+	closed := testClosedPort(t)
+	err = waitForPort(ctx, "127.0.0.1", closed, false, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "to accept connections") {
+		t.Errorf("expected timeout waiting for closed port, got %v", err)
+	}
+
+	// Cancelling stops the wait early
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	start := time.Now()
+	if err := waitForPort(cancelled, "127.0.0.1", closed, false, time.Minute); err == nil {
+		t.Errorf("expected an error once cancelled")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("expected cancelling to stop the wait")
+	}
+}
+
+func TestWaitForPortStartsLater(t *testing.T) {
+	port := testClosedPort(t)
+	done := make(chan error)
+	go func() {
+		done <- waitForPort(context.Background(), "127.0.0.1", port, true, 5*time.Second)
+	}()
+
+	// sshd starts after the first attempts have failed
+	time.Sleep(50 * time.Millisecond)
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+			conn.Close()
+		}
+	}()
+
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}